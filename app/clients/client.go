@@ -5,9 +5,9 @@ import (
 )
 
 type Interface interface {
-	Subscribe(*runtime.Runtime)
+	Subscribe(*runtime.Router)
 }
 
 type Client struct {
-	runtime *runtime.Runtime
+	router *runtime.Router
 }
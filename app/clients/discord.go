@@ -5,13 +5,15 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
-	"GoWorkerAI/app/models"
 	"GoWorkerAI/app/runtime"
 	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/utils"
 )
 
 var _ Interface = &DiscordClient{}
@@ -62,11 +64,38 @@ func NewDiscordClientFromConfig(config map[string]string) (*DiscordClient, error
 	return dc, nil
 }
 
-func (c *DiscordClient) Subscribe(rt *runtime.Runtime) {
-	c.runtime = rt
+func (c *DiscordClient) Subscribe(router *runtime.Router) {
+	c.router = router
+	router.Subscribe(c.onLifecycleEvent)
 	c.Open()
 }
 
+// onLifecycleEvent posts the progress of the tasks on the channel that created
+// them, or on the default channel for tasks from other origins.
+func (c *DiscordClient) onLifecycleEvent(ev runtime.LifecycleEvent) {
+	switch ev.Type {
+	case runtime.TaskStarted, runtime.PlanCreated, runtime.SummaryUpdated, runtime.TaskFinished,
+		runtime.TaskFailed, runtime.TaskPaused, runtime.TaskResumed, runtime.ApprovalRequired, runtime.IssueReported:
+	default:
+		return
+	}
+
+	channelID := c.channelID
+	if ev.Origin == originDiscord && ev.Channel != "" {
+		channelID = ev.Channel
+	}
+	if channelID == "" {
+		return
+	}
+	message := ev.Message()
+	if len(c.router.Teams()) > 1 {
+		message = fmt.Sprintf("[%s] %s", ev.Team, message)
+	}
+	if err := c.SendMessage(channelID, utils.Truncate(message, 1900)); err != nil {
+		log.Printf("⚠️ Error sending %s event for task %s: %v", ev.Type, ev.TaskID, err)
+	}
+}
+
 func (c *DiscordClient) Open() error {
 	if err := c.session.Open(); err != nil {
 		return err
@@ -80,25 +109,66 @@ func (c *DiscordClient) Close() error {
 }
 
 func (c *DiscordClient) onMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
+	defer utils.RecoverPanic("discord message", nil)
 	ctx := context.Background()
 	if m.Author.ID == s.State.User.ID {
 		return
 	}
-	content := fmt.Sprintf("ChannelID: %s\nUserID %s\nUserName: %s\nMessage: %s",
-		m.ChannelID, m.Author.ID, m.Author.Username, m.Content)
-	if err := c.runtime.SaveEventOnHistory(ctx, content, models.UserRole); err != nil {
-		log.Printf("⚠️ Error saving event: %v", err)
-	}
+	session := runtime.SessionKey{Origin: originDiscord, Channel: m.ChannelID, UserID: m.Author.ID}
 	contentSplitted := strings.Fields(m.Content)
 	var msg string
 	switch strings.ToLower(contentSplitted[0]) {
 	case "status":
 		msg = c.getStatus(s, m)
 	case "help", "!help":
-		msg = "Supported commands: !help, !task"
+		msg = "Supported commands: !help, !reset, !task, !approve, !reject, !answer"
+	case "!reset":
+		msg = "Conversation reset, I don't remember our previous messages in this channel anymore."
+		if err := c.router.Default().ResetSession(ctx, session); err != nil {
+			msg = "Couldn't reset the conversation: " + err.Error()
+		}
+	case "!approve", "!reject":
+		if m.Author.ID != os.Getenv("DISCORD_ADMIN") {
+			msg = "You are not authorized to use this command."
+			break
+		}
+		if len(contentSplitted) < 2 {
+			msg = c.getPendingApprovals()
+			break
+		}
+		decision := runtime.ApprovalDecision{
+			Approved: strings.ToLower(contentSplitted[0]) == "!approve",
+			Approver: fmt.Sprintf("%s (%s)", m.Author.Username, m.Author.ID),
+			Reason:   strings.Join(contentSplitted[2:], " "),
+		}
+		if err := c.router.ResolveApproval(contentSplitted[1], decision); err != nil {
+			msg = "Couldn't resolve approval: " + err.Error()
+			break
+		}
+		msg = "Approval " + contentSplitted[1] + " " + decision.String() + "."
+	case "!answer":
+		if m.Author.ID != os.Getenv("DISCORD_ADMIN") {
+			msg = "You are not authorized to use this command."
+			break
+		}
+		if len(contentSplitted) < 3 {
+			msg = "Usage: !answer <issue id> <answer>. Open issues:\n" + c.router.GetOpenIssuesText(ctx)
+			break
+		}
+		id, err := runtime.ParseIssueID(contentSplitted[1])
+		if err != nil {
+			msg = err.Error()
+			break
+		}
+		answeredBy := fmt.Sprintf("%s (%s)", m.Author.Username, m.Author.ID)
+		if err = c.router.AnswerIssue(ctx, id, strings.Join(contentSplitted[2:], " "), answeredBy); err != nil {
+			msg = "Couldn't answer the issue: " + err.Error()
+			break
+		}
+		msg = fmt.Sprintf("Issue %d answered, the leader will see it on its next delegation.", id)
 	case "!task":
 		if len(contentSplitted) < 2 {
-			msg = "Usage: !task create <description> | !task cancel | !task status"
+			msg = "Usage: !task create [--team name] [--priority N] [--max-steps N] [--max-tokens N] [--max-duration 30m] <description> | !task cancel [task id] | !task pause <task id> | !task resume <task id> | !task say <task id> <message> | !task result <task id> | !task status | !task queue | !task schedules | !task teams"
 			break
 		}
 		if m.Author.ID != os.Getenv("DISCORD_ADMIN") {
@@ -109,29 +179,85 @@ func (c *DiscordClient) onMessageCreate(s *discordgo.Session, m *discordgo.Messa
 		cmd := contentSplitted[1]
 		switch cmd {
 		case "create":
-			description := strings.Join(contentSplitted[2:], " ")
-			description = description + "\n Rule: Must notify on discord (channel id: " + m.ChannelID + ") when you finish."
-			newTask := teams.Task{
-				Description: description,
-			}
+			newTask, team, args := parseTaskFlags(contentSplitted[2:])
+			newTask.Description = strings.Join(args, " ")
+			newTask.Channel = m.ChannelID
 			ev := runtime.Event{
 				Origin:      originDiscord,
+				Team:        team,
 				Task:        &newTask,
 				HandlerFunc: runtime.EventsHandlerFuncDefault[runtime.NewTask],
 			}
-			c.runtime.QueueEvent(ev)
-			msg = "New task created, processing..."
+			msg = "New task queued, it will start as soon as a slot is free."
+			if err := c.router.QueueEvent(ev); err != nil {
+				msg = "Couldn't queue the task: " + err.Error()
+			}
 		case "cancel":
 			ev := runtime.Event{
 				Origin:      originDiscord,
 				HandlerFunc: runtime.EventsHandlerFuncDefault[runtime.CancelTask],
 			}
-			c.runtime.QueueEvent(ev)
-			msg = "Active task cancelled."
+			if len(contentSplitted) < 3 {
+				c.router.Broadcast(ev)
+				msg = "Active tasks cancelled."
+				break
+			}
+			ev.TaskID = contentSplitted[2]
+			msg = "Task " + ev.TaskID + " cancelled."
+			c.router.QueueEvent(ev)
+		case "resume":
+			if len(contentSplitted) < 3 {
+				msg = "Usage: !task resume <task id>"
+				break
+			}
+			ev := runtime.Event{
+				Origin:      originDiscord,
+				TaskID:      contentSplitted[2],
+				HandlerFunc: runtime.EventsHandlerFuncDefault[runtime.ResumeTask],
+			}
+			c.router.QueueEvent(ev)
+			msg = "Task " + ev.TaskID + " will resume."
+		case "pause":
+			if len(contentSplitted) < 3 {
+				msg = "Usage: !task pause <task id>"
+				break
+			}
+			ev := runtime.Event{
+				Origin:      originDiscord,
+				TaskID:      contentSplitted[2],
+				HandlerFunc: runtime.EventsHandlerFuncDefault[runtime.PauseTask],
+			}
+			c.router.QueueEvent(ev)
+			msg = "Task " + ev.TaskID + " will pause after its current step."
+		case "say":
+			if len(contentSplitted) < 4 {
+				msg = "Usage: !task say <task id> <message>"
+				break
+			}
+			ev := runtime.Event{
+				Origin:      originDiscord,
+				TaskID:      contentSplitted[2],
+				Message:     strings.Join(contentSplitted[3:], " "),
+				HandlerFunc: runtime.EventsHandlerFuncDefault[runtime.InjectMessage],
+			}
+			c.router.QueueEvent(ev)
+			msg = "Message sent to task " + ev.TaskID + ", the leader will see it on its next delegation."
+		case "result":
+			if len(contentSplitted) < 3 {
+				msg = "Usage: !task result <task id>"
+				break
+			}
+			msg = c.router.GetTaskResultText(ctx, contentSplitted[2])
 		case "status":
 			msg = c.getStatus(s, m)
+		case "queue":
+			msg = c.router.GetQueueStatusText(ctx)
+		case "teams":
+			msg = "Teams: " + strings.Join(c.router.Teams(), ", ")
+		case "schedules":
+			msg = c.router.GetSchedulesText(ctx)
 		default:
-			msg = "Unknown task command. Use: !task with create | cancel | status"
+			msg = "Unknown task command. Use: !task with create | cancel | pause | resume | say | result | status | queue | schedules | teams"
 		}
 	default:
 		isMentioned := false
@@ -143,17 +269,64 @@ func (c *DiscordClient) onMessageCreate(s *discordgo.Session, m *discordgo.Messa
 		if !isMentioned {
 			return
 		}
-		msg = c.runtime.ProcessQuickEvent(ctx, content)
+		content := fmt.Sprintf("ChannelID: %s\nUserID %s\nUserName: %s\nMessage: %s",
+			m.ChannelID, m.Author.ID, m.Author.Username, m.Content)
+		msg = c.router.ProcessQuickEvent(ctx, session, content, m.Author.ID == os.Getenv("DISCORD_ADMIN"))
 	}
 	s.ChannelMessageSend(m.ChannelID, msg)
 }
 
+// parseTaskFlags reads the leading --flag value pairs of a task command and
+// returns the task they describe, its team and the remaining words.
+func parseTaskFlags(args []string) (teams.Task, string, []string) {
+	var task teams.Task
+	var team string
+	var budget teams.Budget
+	for len(args) >= 2 && strings.HasPrefix(args[0], "--") {
+		value := args[1]
+		switch args[0] {
+		case "--team":
+			team = value
+		case "--priority":
+			task.Priority, _ = strconv.Atoi(value)
+		case "--max-steps":
+			budget.MaxSteps, _ = strconv.Atoi(value)
+		case "--max-tokens":
+			budget.MaxTokens, _ = strconv.Atoi(value)
+		case "--max-failures":
+			budget.MaxConsecutiveFailures, _ = strconv.Atoi(value)
+		case "--max-duration":
+			budget.MaxDuration, _ = time.ParseDuration(value)
+		default:
+			return task, team, args
+		}
+		args = args[2:]
+	}
+	if !budget.IsZero() {
+		task.Budget = &budget
+	}
+	return task, team, args
+}
+
+func (c *DiscordClient) getPendingApprovals() string {
+	approvals := c.router.PendingApprovals()
+	if len(approvals) == 0 {
+		return "No tool calls waiting for approval."
+	}
+	lines := []string{"Usage: !approve <id> | !reject <id> <reason>. Pending approvals:"}
+	for _, a := range approvals {
+		lines = append(lines, fmt.Sprintf("- %s: %s wants to run %s with %s", a.ID, a.Member, a.Tool, a.Arguments))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (c *DiscordClient) getStatus(s *discordgo.Session, m *discordgo.MessageCreate) string {
 	s.ChannelMessageSend(m.ChannelID, "Processing...")
-	return c.runtime.GetTaskStatus()
+	return c.router.GetTaskStatus()
 }
 
 func (c *DiscordClient) onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer utils.RecoverPanic("discord interaction", nil)
 	if i.Type == discordgo.InteractionApplicationCommand {
 		switch i.ApplicationCommandData().Name {
 		case "task":
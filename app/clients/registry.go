@@ -26,12 +26,12 @@ func NewRegistry() *Registry {
 	}
 }
 
-func (r *Registry) Register(client Interface, rt *runtime.Runtime) error {
+func (r *Registry) Register(client Interface, router *runtime.Router) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	r.clients = append(r.clients, client)
-	client.Subscribe(rt)
+	client.Subscribe(router)
 
 	return nil
 }
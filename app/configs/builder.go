@@ -12,10 +12,23 @@ import (
 	"GoWorkerAI/app/tools"
 )
 
-func (tc TeamConfig) BuildTeam(ctx context.Context, mcpRegistry *mcps.Registry) (*teams.Team, error) {
+// BuildTeam builds the team and its members, subTeam builds the teams used as members.
+func (tc TeamConfig) BuildTeam(ctx context.Context, name string, mcpRegistry *mcps.Registry,
+	subTeam func(name string) (*teams.Team, error)) (*teams.Team, error) {
 	var members []*teams.Member
 
 	for _, mc := range tc.Members {
+		if mc.Team != "" {
+			sub, err := subTeam(mc.Team)
+			if err != nil {
+				return nil, fmt.Errorf("build team %s for member %s: %w", mc.Team, mc.Key, err)
+			}
+			member := teams.NewMember(mc.Key, mc.System, mc.WhenCall, &teams.Worker{System: mc.System, Rules: mc.Rules})
+			member.SubTeam = sub
+			members = append(members, member)
+			continue
+		}
+
 		worker, err := mc.BuildWorker()
 		if err != nil {
 			return nil, fmt.Errorf("build worker %s: %w", mc.Key, err)
@@ -40,10 +53,18 @@ func (tc TeamConfig) BuildTeam(ctx context.Context, mcpRegistry *mcps.Registry)
 		}
 
 		member := teams.NewMember(mc.Key, mc.System, mc.WhenCall, worker)
+		member.RequiresApproval = append(append([]string{}, tc.RequiresApproval...), mc.RequiresApproval...)
+		member.MaxToolRounds = mc.MaxToolRounds
+		member.Settings = tc.Settings.Merge(mc.Settings)
 		members = append(members, member)
 	}
 
-	return teams.NewTeam(members, tc.Task), nil
+	team := teams.NewTeam(name, members, tc.Task)
+	team.Budget = tc.Budget
+	team.Review = tc.Review
+	team.Workflow = tc.Workflow
+	team.Completion = tc.Completion
+	return team, nil
 }
 
 func (c *Config) BuildTeamByName(ctx context.Context, teamName string, mcpRegistry *mcps.Registry) (*teams.Team, error) {
@@ -52,7 +73,9 @@ func (c *Config) BuildTeamByName(ctx context.Context, teamName string, mcpRegist
 		return nil, fmt.Errorf("team %s not found in configs", teamName)
 	}
 
-	return teamCfg.BuildTeam(ctx, mcpRegistry)
+	return teamCfg.BuildTeam(ctx, teamName, mcpRegistry, func(name string) (*teams.Team, error) {
+		return c.BuildTeamByName(ctx, name, mcpRegistry)
+	})
 }
 
 func (c *Config) StartGlobalMCPs(ctx context.Context, mcpRegistry *mcps.Registry) error {
@@ -65,7 +88,7 @@ func (c *Config) StartGlobalMCPs(ctx context.Context, mcpRegistry *mcps.Registry
 	return nil
 }
 
-func (c *Config) InitializeClients(clientRegistry *clients.Registry, rt *runtime.Runtime) error {
+func (c *Config) InitializeClients(clientRegistry *clients.Registry, router *runtime.Router) error {
 	if len(c.Clients) == 0 {
 		log.Println("ℹ️ No clients configured")
 		return nil
@@ -83,7 +106,7 @@ func (c *Config) InitializeClients(clientRegistry *clients.Registry, rt *runtime
 			return fmt.Errorf("failed to create %s client: %w", clientCfg.Type, err)
 		}
 
-		if err := clientRegistry.Register(client, rt); err != nil {
+		if err := clientRegistry.Register(client, router); err != nil {
 			return fmt.Errorf("failed to register %s client: %w", clientCfg.Type, err)
 		}
 
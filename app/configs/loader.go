@@ -3,24 +3,44 @@ package configs
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 
 	"GoWorkerAI/app/clients"
 	"GoWorkerAI/app/mcps"
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/runtime"
 	"GoWorkerAI/app/teams"
 	"GoWorkerAI/app/tools"
 )
 
 type Config struct {
 	Teams      map[string]TeamConfig `yaml:"teams"`
+	Runtime    runtime.Config        `yaml:"runtime,omitempty"`
+	Schedules  []runtime.Schedule    `yaml:"schedules,omitempty"`
 	Clients    []clients.Config      `yaml:"clients,omitempty"`
 	GlobalMCPs []mcps.Config         `yaml:"global_mcps,omitempty"`
 }
 
 type TeamConfig struct {
-	Task    string         `yaml:"task"`
-	Members []MemberConfig `yaml:"members"`
+	// Task is queued when the team starts, it can be empty for a team only used as a sub-team.
+	Task string `yaml:"task,omitempty"`
+	// RunOnStart queues the task of a team other than the default one at startup.
+	RunOnStart bool               `yaml:"run_on_start,omitempty"`
+	Budget     teams.Budget       `yaml:"budget,omitempty"`
+	Review     teams.ReviewPolicy `yaml:"review,omitempty"`
+	// Workflow runs fixed steps instead of letting the leader delegate the task.
+	Workflow *teams.Workflow `yaml:"workflow,omitempty"`
+	// Completion decides when the leader has finished the task, the leader model judges it by default.
+	Completion *teams.Completion `yaml:"completion,omitempty"`
+	// RequiresApproval lists the tools that need a human approval for every member of the team,
+	// a member backed by a team follows the config of that team instead.
+	RequiresApproval []string `yaml:"requires_approval,omitempty"`
+	// Settings are the model, endpoint and sampling of every member of the team, but for
+	// the members backed by a team.
+	models.Settings `yaml:",inline"`
+	Members         []MemberConfig `yaml:"members"`
 }
 
 type MemberConfig struct {
@@ -30,6 +50,16 @@ type MemberConfig struct {
 	ToolsPreset string        `yaml:"tools_preset,omitempty"`
 	Rules       []string      `yaml:"rules,omitempty"`
 	MCPs        []mcps.Config `yaml:"mcps,omitempty"`
+	// RequiresApproval lists the tools of this member that need a human approval, "*" for all of them.
+	RequiresApproval []string `yaml:"requires_approval,omitempty"`
+	// MaxToolRounds is how many times the member can call tools and read their results
+	// before it must answer, defaults to 5.
+	MaxToolRounds int `yaml:"max_tool_rounds,omitempty"`
+	// Settings override the model, endpoint and sampling of the team for this member.
+	models.Settings `yaml:",inline"`
+	// Team makes the member a whole team of the config, the leader delegates
+	// objectives to it and receives its final answer.
+	Team string `yaml:"team,omitempty"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -61,7 +91,7 @@ func (mc MemberConfig) BuildWorker() (teams.Interface, error) {
 	case "reviewer":
 		worker.ToolsPreset = tools.PresetApprover
 	case "event_handler":
-		worker.ToolsPreset = "" // No tools
+		worker.ToolsPreset = tools.PresetEventHandler
 	}
 	return worker, nil
 }
@@ -71,20 +101,82 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("no teams defined in configs")
 	}
 
+	subTeams := make(map[string]bool)
+	for _, teamCfg := range c.Teams {
+		for _, member := range teamCfg.Members {
+			if member.Team != "" {
+				subTeams[member.Team] = true
+			}
+		}
+	}
+
 	for teamName, teamCfg := range c.Teams {
+		if teamCfg.Task == "" && (teamCfg.RunOnStart || !subTeams[teamName]) {
+			return fmt.Errorf("team %s: task cannot be empty", teamName)
+		}
 		if err := teamCfg.Validate(); err != nil {
 			return fmt.Errorf("team %s: %w", teamName, err)
 		}
+		// Without a workspace the checks would run on the folder of the process.
+		if teamCfg.Completion != nil && teamCfg.Completion.NeedsWorkspace() && c.Runtime.Workspace == "" {
+			return fmt.Errorf("team %s: completion %s needs runtime.workspace or WORKER_FOLDER", teamName,
+				teamCfg.Completion.Type)
+		}
+		if err := c.validateSubTeams(teamName, nil); err != nil {
+			return fmt.Errorf("team %s: %w", teamName, err)
+		}
+	}
+
+	if err := c.Runtime.Validate(); err != nil {
+		return fmt.Errorf("runtime: %w", err)
+	}
+
+	names := make(map[string]bool, len(c.Schedules))
+	for _, schedule := range c.Schedules {
+		if err := schedule.Validate(); err != nil {
+			return err
+		}
+		if names[schedule.Name] {
+			return fmt.Errorf("duplicated schedule %s", schedule.Name)
+		}
+		names[schedule.Name] = true
+		if _, ok := c.Teams[schedule.Team]; !ok {
+			return fmt.Errorf("schedule %s: team %s not found", schedule.Name, schedule.Team)
+		}
 	}
 
 	return nil
 }
 
-func (tc TeamConfig) Validate() error {
-	if tc.Task == "" {
-		return fmt.Errorf("task cannot be empty")
+// validateSubTeams checks that the teams used as members exist and never
+// contain the team that uses them.
+func (c *Config) validateSubTeams(teamName string, path []string) error {
+	path = append(path, teamName)
+	for _, member := range c.Teams[teamName].Members {
+		if member.Team == "" {
+			continue
+		}
+		switch member.Key {
+		case "leader", "reviewer", "event_handler":
+			return fmt.Errorf("member %s cannot be a team", member.Key)
+		}
+		if _, ok := c.Teams[member.Team]; !ok {
+			return fmt.Errorf("member %s: team %s not found", member.Key, member.Team)
+		}
+		for _, name := range path {
+			if name == member.Team {
+				return fmt.Errorf("member %s: team %s contains itself (%s -> %s)",
+					member.Key, member.Team, strings.Join(path, " -> "), member.Team)
+			}
+		}
+		if err := c.validateSubTeams(member.Team, path); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+func (tc TeamConfig) Validate() error {
 	if len(tc.Members) == 0 {
 		return fmt.Errorf("no members defined")
 	}
@@ -101,5 +193,82 @@ func (tc TeamConfig) Validate() error {
 		return fmt.Errorf("team must have a 'leader' member")
 	}
 
+	if tc.Review.Enabled {
+		hasReviewer := false
+		for _, member := range tc.Members {
+			if member.Key == "reviewer" {
+				hasReviewer = true
+				break
+			}
+		}
+		if !hasReviewer {
+			return fmt.Errorf("review is enabled but the team has no 'reviewer' member")
+		}
+	}
+
+	keys := make([]string, 0, len(tc.Members))
+	for _, member := range tc.Members {
+		keys = append(keys, member.Key)
+	}
+	if tc.Workflow != nil {
+		if err := tc.Workflow.Validate(keys); err != nil {
+			return err
+		}
+	}
+	if tc.Completion != nil {
+		if err := tc.Completion.Validate(keys); err != nil {
+			return err
+		}
+	}
+
+	if err := tc.Settings.Validate(); err != nil {
+		return err
+	}
+	for _, member := range tc.Members {
+		if member.Team != "" {
+			if err := member.validateSubTeam(); err != nil {
+				return err
+			}
+			continue
+		}
+		if member.MaxToolRounds < 0 {
+			return fmt.Errorf("member %s: max_tool_rounds cannot be negative", member.Key)
+		}
+		if err := member.Settings.Validate(); err != nil {
+			return fmt.Errorf("member %s: %w", member.Key, err)
+		}
+	}
+
+	if tc.Budget.MaxSteps < 0 || tc.Budget.MaxConsecutiveFailures < 0 || tc.Budget.MaxTokens < 0 ||
+		tc.Budget.MaxDuration < 0 {
+		return fmt.Errorf("budget limits cannot be negative")
+	}
+
+	return nil
+}
+
+// validateSubTeam rejects the worker options on a member backed by a team, its
+// own workers run with the options of that team.
+func (mc MemberConfig) validateSubTeam() error {
+	var options []string
+	if mc.ToolsPreset != "" {
+		options = append(options, "tools_preset")
+	}
+	if len(mc.MCPs) > 0 {
+		options = append(options, "mcps")
+	}
+	if len(mc.RequiresApproval) > 0 {
+		options = append(options, "requires_approval")
+	}
+	if mc.MaxToolRounds != 0 {
+		options = append(options, "max_tool_rounds")
+	}
+	if !mc.Settings.IsZero() {
+		options = append(options, "model settings")
+	}
+	if len(options) > 0 {
+		return fmt.Errorf("member %s: %s cannot be set on a member backed by team %s, set them on that team",
+			mc.Key, strings.Join(options, ", "), mc.Team)
+	}
 	return nil
 }
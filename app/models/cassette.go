@@ -0,0 +1,200 @@
+package models
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"GoWorkerAI/app/utils/restclient"
+)
+
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+
+	defaultCassettePath = "data/cassette.jsonl"
+)
+
+var _ restclient.Interface = &Cassette{}
+
+// Cassette sits between the LLM client and the server. In record mode it saves
+// every request with its response, in replay mode it answers from the file
+// without a server, so a task run can be reproduced exactly.
+type Cassette struct {
+	mode string
+	path string
+	next restclient.Interface
+
+	mu      sync.Mutex
+	file    *os.File
+	entries map[string][]cassetteEntry
+}
+
+// cassetteEntry is a line of the cassette file, requests are matched by the
+// hash of their endpoint and body. The same request recorded several times is
+// replayed in the recorded order.
+type cassetteEntry struct {
+	Hash     string          `json:"hash"`
+	Endpoint string          `json:"endpoint"`
+	Request  json.RawMessage `json:"request"`
+	Status   int             `json:"status"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// NewCassette opens the cassette at path, record mode starts a new file and
+// replay mode loads the recorded requests. next is only used to record.
+func NewCassette(mode, path string, next restclient.Interface) (*Cassette, error) {
+	if path == "" {
+		path = defaultCassettePath
+	}
+	c := &Cassette{mode: mode, path: path, next: next, entries: make(map[string][]cassetteEntry)}
+
+	switch mode {
+	case CassetteRecord:
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return nil, err
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("create cassette: %w", err)
+		}
+		c.file = file
+	case CassetteReplay:
+		if err := c.load(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %q, use %s or %s", mode, CassetteRecord, CassetteReplay)
+	}
+	log.Printf("📼 LLM cassette in %s mode: %s", mode, path)
+	return c, nil
+}
+
+func (c *Cassette) load() error {
+	file, err := os.Open(c.path)
+	if err != nil {
+		return fmt.Errorf("open cassette: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry cassetteEntry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("cassette %s line %d: %w", c.path, line, err)
+		}
+		c.entries[entry.Hash] = append(c.entries[entry.Hash], entry)
+	}
+	return scanner.Err()
+}
+
+func (c *Cassette) Post(ctx context.Context, endpoint string, body any, headers map[string]string) ([]byte, int, error) {
+	return c.post(ctx, c.next, endpoint, body, headers)
+}
+
+// through returns the cassette recording the requests sent to next, for the
+// members with their own endpoint. It shares the file of the cassette.
+func (c *Cassette) through(next restclient.Interface) restclient.Interface {
+	return cassetteEndpoint{Cassette: c, next: next}
+}
+
+type cassetteEndpoint struct {
+	*Cassette
+	next restclient.Interface
+}
+
+func (e cassetteEndpoint) Post(ctx context.Context, endpoint string, body any, headers map[string]string) ([]byte, int, error) {
+	return e.Cassette.post(ctx, e.next, endpoint, body, headers)
+}
+
+func (c *Cassette) post(ctx context.Context, next restclient.Interface, endpoint string, body any,
+	headers map[string]string) ([]byte, int, error) {
+	request, err := json.Marshal(body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cassette: marshal request: %w", err)
+	}
+	hash := requestHash(endpoint, request)
+
+	if c.mode == CassetteReplay {
+		return c.replay(hash, endpoint)
+	}
+
+	response, status, err := next.Post(ctx, endpoint, body, headers)
+	entry := cassetteEntry{Hash: hash, Endpoint: endpoint, Request: request, Status: status}
+	if json.Valid(response) {
+		entry.Response = response
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	c.record(entry)
+	return response, status, err
+}
+
+func (c *Cassette) replay(hash, endpoint string) ([]byte, int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	recorded := c.entries[hash]
+	if len(recorded) == 0 {
+		err := fmt.Errorf("cassette: no recorded response for %s request %s, the run diverged from %s",
+			endpoint, hash[:12], c.path)
+		log.Printf("❌ %v", err)
+		return nil, 0, err
+	}
+	entry := recorded[0]
+	c.entries[hash] = recorded[1:]
+
+	if entry.Error != "" {
+		return entry.Response, entry.Status, errors.New(entry.Error)
+	}
+	return entry.Response, entry.Status, nil
+}
+
+func (c *Cassette) record(entry cassetteEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("⚠️ Error encoding cassette entry %s: %v", entry.Hash, err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err = c.file.Write(append(line, '\n')); err != nil {
+		log.Printf("⚠️ Error writing cassette %s: %v", c.path, err)
+	}
+}
+
+func (c *Cassette) Get(ctx context.Context, endpoint string, headers map[string]string) ([]byte, int, error) {
+	return nil, 0, fmt.Errorf("cassette: GET %s is not supported", endpoint)
+}
+
+func (c *Cassette) Put(ctx context.Context, endpoint string, body any, headers map[string]string) ([]byte, int, error) {
+	return nil, 0, fmt.Errorf("cassette: PUT %s is not supported", endpoint)
+}
+
+func (c *Cassette) Delete(ctx context.Context, endpoint string, headers map[string]string) ([]byte, int, error) {
+	return nil, 0, fmt.Errorf("cassette: DELETE %s is not supported", endpoint)
+}
+
+func (c *Cassette) Close() error {
+	if c.file == nil {
+		return nil
+	}
+	return c.file.Close()
+}
+
+func requestHash(endpoint string, request []byte) string {
+	sum := sha256.Sum256(append([]byte(endpoint+"\n"), request...))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoWorkerAI/app/utils/restclient"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"answer"}}]}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	recorder, err := NewCassette(CassetteRecord, path, restclient.NewRestClient(server.URL, nil))
+	assert.NoError(t, err)
+	payload := requestPayload{Model: "test", Messages: CreateMessages("hi", "system")}
+	recorded, status, err := recorder.Post(ctx, endpoint, payload, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, recorder.Close())
+
+	player, err := NewCassette(CassetteReplay, path, nil)
+	assert.NoError(t, err)
+	replayed, status, err := player.Post(ctx, endpoint, payload, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, string(recorded), string(replayed))
+	assert.Equal(t, 1, calls)
+
+	_, _, err = player.Post(ctx, endpoint, payload, nil)
+	assert.Error(t, err, "every recorded response is served once")
+
+	payload.Messages = CreateMessages("something else", "system")
+	_, _, err = player.Post(ctx, endpoint, payload, nil)
+	assert.Error(t, err)
+}
+
+func TestNewCassetteErrors(t *testing.T) {
+	_, err := NewCassette("live", "", nil)
+	assert.Error(t, err)
+
+	_, err = NewCassette(CassetteReplay, filepath.Join(t.TempDir(), "missing.jsonl"), nil)
+	assert.Error(t, err)
+}
+
+func TestCassetteRecordsMemberEndpoint(t *testing.T) {
+	ctx := context.Background()
+	served := map[string]int{}
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served[name]++
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"` + name + `"}}]}`))
+		}))
+	}
+	defaultServer, memberServer := newServer("default"), newServer("member")
+	defer defaultServer.Close()
+	defer memberServer.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	recorder, err := NewCassette(CassetteRecord, path, restclient.NewRestClient(defaultServer.URL, nil))
+	assert.NoError(t, err)
+	client := newLLMClient(nil, "test", "", recorder).WithSettings(Settings{BaseURL: memberServer.URL}).(*LLMClient)
+	payload := requestPayload{Model: "test", Messages: CreateMessages("hi", "system")}
+	_, _, err = client.restClient.Post(ctx, endpoint, payload, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.Close())
+	assert.Equal(t, map[string]int{"member": 1}, served)
+
+	player, err := NewCassette(CassetteReplay, path, nil)
+	assert.NoError(t, err)
+	replayed, _, err := player.Post(ctx, endpoint, payload, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, string(replayed), "member")
+}
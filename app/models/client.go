@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -22,6 +24,8 @@ import (
 const (
 	endpoint          = "/v1/chat/completions"
 	embeddingEndpoint = "/v1/embeddings"
+
+	defaultMaxToolRounds = 5
 )
 
 var _ Interface = &LLMClient{}
@@ -29,31 +33,115 @@ var _ Interface = &LLMClient{}
 var v = validator.New()
 
 type LLMClient struct {
-	restClient      *restclient.RestClient
+	restClient      restclient.Interface
 	storage         storage.Interface
 	cache           sync.Map
 	model           string
 	embeddingsModel string
+	// settings holds the sampling of a client made by WithSettings.
+	settings Settings
 }
 
+// NewLLMClient connects to the server at LLM_BASE_URL. When LLM_CASSETTE_MODE is set
+// the requests are recorded to or replayed from the cassette at LLM_CASSETTE_PATH.
 func NewLLMClient(db storage.Interface, model, embModel string) *LLMClient {
-	baseURL := os.Getenv("LLM_BASE_URL")
-	if baseURL == "" {
-		baseURL = "http://localhost:1234"
+	mode := os.Getenv("LLM_CASSETTE_MODE")
+	if mode == "" {
+		return newLLMClient(db, model, embModel, newRestClient("", ""))
+	}
+	client, err := NewCassetteClient(db, model, embModel, mode, os.Getenv("LLM_CASSETTE_PATH"))
+	if err != nil {
+		log.Fatalf("❌ Error opening LLM cassette: %v", err)
+	}
+	return client
+}
+
+// NewCassetteClient returns a client that records its requests to the cassette
+// or, in replay mode, answers them from it without a server.
+func NewCassetteClient(db storage.Interface, model, embModel, mode, path string) (*LLMClient, error) {
+	var next restclient.Interface
+	if mode == CassetteRecord {
+		next = newRestClient("", "")
+	}
+	cassette, err := NewCassette(mode, path, next)
+	if err != nil {
+		return nil, err
 	}
+	return newLLMClient(db, model, embModel, cassette), nil
+}
+
+func newLLMClient(db storage.Interface, model, embModel string, restClient restclient.Interface) *LLMClient {
 	return &LLMClient{
-		restClient:      restclient.NewRestClient(baseURL, nil),
+		restClient:      restClient,
 		storage:         db,
 		model:           model,
 		embeddingsModel: embModel,
 	}
 }
 
+// newRestClient connects to the base URL, LLM_BASE_URL when empty, with the
+// API key or LLM_API_KEY as bearer token.
+func newRestClient(baseURL, apiKey string) *restclient.RestClient {
+	if baseURL == "" {
+		baseURL = os.Getenv("LLM_BASE_URL")
+	}
+	if baseURL == "" {
+		baseURL = "http://localhost:1234"
+	}
+	if apiKey == "" {
+		apiKey = os.Getenv("LLM_API_KEY")
+	}
+	var headers map[string]string
+	if apiKey != "" {
+		headers = map[string]string{"Authorization": "Bearer " + apiKey}
+	}
+	return restclient.NewRestClient(baseURL, headers)
+}
+
+// WithSettings returns a client for the model and the endpoint of the settings,
+// with their sampling applied to every call. A client replaying or recording a
+// cassette keeps it for every model, recording the requests sent to the endpoint.
+func (mc *LLMClient) WithSettings(s Settings) Interface {
+	if s.IsZero() {
+		return mc
+	}
+	client := newLLMClient(mc.storage, mc.model, mc.embeddingsModel, mc.restClient)
+	client.settings = mc.settings.Merge(s)
+	if s.Model != "" {
+		client.model = s.Model
+	}
+	if s.BaseURL != "" || s.APIKey != "" {
+		var next restclient.Interface = newRestClient(client.settings.BaseURL, client.settings.APIKey)
+		if cassette := mc.cassette(); cassette != nil {
+			next = cassette.through(next)
+		}
+		client.restClient = next
+	}
+	return client
+}
+
+func (mc *LLMClient) cassette() *Cassette {
+	switch rc := mc.restClient.(type) {
+	case *Cassette:
+		return rc
+	case cassetteEndpoint:
+		return rc.Cassette
+	}
+	return nil
+}
+
+func (mc *LLMClient) TokenEstimator() TokenEstimator {
+	return NewTokenEstimator(mc.model)
+}
+
 func (mc *LLMClient) Think(ctx context.Context, messages []Message, temp float64, maxTokens int) (string, error) {
 	response, err := mc.generateResponse(ctx, messages, nil, temp, maxTokens, NoneToolChoice)
 	if err != nil {
 		return "", err
 	}
+	if len(response.Choices) == 0 {
+		return "", errors.New("empty LLM response")
+	}
 	return response.Choices[0].Message.Content, nil
 }
 
@@ -97,11 +185,17 @@ func (mc *LLMClient) TrueOrFalse(ctx context.Context, msgs []Message) (bool, str
 	return false, "", fmt.Errorf("yes/no: model did not call approve_plan or reject_plan after retries")
 }
 
-func (mc *LLMClient) Delegate(ctx context.Context, options, context, sysPrompt string) (*DelegateAction, error) {
+// Delegate asks the model for the next subtasks, restricting the worker to the
+// given member keys. Invalid choices are sent back to the model to correct them.
+func (mc *LLMClient) Delegate(ctx context.Context, options, context, sysPrompt string,
+	workers []string) ([]DelegateAction, error) {
 	sys := Message{
 		Role: "system",
 		Content: sysPrompt + `Tooling policy:
 - Use tool delegate_task to delegate atomic subtasks to a specific worker.
+- Use tool delegate_tasks to delegate several independent subtasks at once; they run in parallel on different workers.
+- Only batch subtasks that do not depend on each other's results. When in doubt, delegate a single subtask.
+- Set step to the number of the plan step each subtask works on, follow the plan order and skip the steps marked done.
 - If no worker fits or information is missing, choose "none" as worker to avoid task.
 - Should always respond with an existing worker key in case you ask for any task.
 - If you are not sure on which worker to delegate, choose the best from the list as assigned worker.
@@ -109,17 +203,17 @@ func (mc *LLMClient) Delegate(ctx context.Context, options, context, sysPrompt s
 	}
 	user := Message{
 		Role: "user",
-		Content: "Context:\n" + context +
+		Content: "Plan:\n" + context +
 			"\nAvailable workers:\n" + options +
-			"\nPick the single best worker and the next subtask now. " +
-			"Always respond by calling delegate_task(Worker, Task, Context). " +
+			"\nPick the best worker and the next subtask now, or several workers with independent subtasks. " +
+			"Always respond by calling delegate_task(Worker, Task, Context) or delegate_tasks(Tasks). " +
 			"If you judge the overall task finished, call delegate_task with Worker=\"none\" and Task=\"finish\".",
 	}
 
 	msgs := []Message{sys, user}
-	toolsPreset := tools.NewToolkitFromPreset(tools.PresetDelegate)
+	toolkit := tools.NewDelegateToolkit(workers)
 	for attempt := 0; attempt < 3; attempt++ {
-		resp, err := mc.generateResponse(ctx, msgs, toolsPreset, 0.13, -1, RequiredToolChoice)
+		resp, err := mc.generateResponse(ctx, msgs, toolkit, 0.13, -1, RequiredToolChoice)
 		if err != nil {
 			return nil, err
 		}
@@ -130,25 +224,72 @@ func (mc *LLMClient) Delegate(ctx context.Context, options, context, sysPrompt s
 		msg := resp.Choices[0].Message
 		if len(msg.ToolCalls) == 0 {
 			log.Printf("Delegate attempt %d: model returned no tool call, content=%v", attempt, msg)
+			msgs = append(msgs, Message{Role: AssistantRole, Content: msg.Content},
+				Message{Role: UserRole, Content: DelegateNoCallPrompt})
 			continue
 		}
 
-		var action DelegateAction
-		if err = json.Unmarshal([]byte(msg.ToolCalls[0].Function.Arguments), &action); err != nil {
-			log.Printf("Delegate attempt %d: model returned invalid tool call arguments: %v", attempt, err)
-			continue
+		actions, err := parseDelegateCalls(msg.ToolCalls)
+		if err == nil {
+			err = checkWorkers(actions, workers)
 		}
-		if err = v.Struct(action); err != nil {
-			log.Printf("Delegate attempt %d: model returned invalid tool call arguments: %v", attempt, err)
+		if err != nil {
+			log.Printf("Delegate attempt %d: model returned an invalid delegation: %v", attempt, err)
+			msgs = append(msgs, Message{Role: AssistantRole, ToolCalls: msg.ToolCalls})
+			for _, call := range msg.ToolCalls {
+				msgs = append(msgs, Message{
+					Role:       ToolRole,
+					Content:    fmt.Sprintf(DelegateCorrectionPrompt, err, strings.Join(workers, ", "), tools.NoWorker),
+					ToolCallID: call.ID,
+				})
+			}
 			continue
 		}
-		return &action, nil
-
+		return actions, nil
 	}
 
 	return nil, fmt.Errorf("delegate: model did not choose any team member after retries")
 }
 
+// checkWorkers rejects the delegations to workers that are not in the team.
+func checkWorkers(actions []DelegateAction, workers []string) error {
+	if len(workers) == 0 {
+		return nil
+	}
+	for _, action := range actions {
+		if action.Worker != tools.NoWorker && !slices.Contains(workers, action.Worker) {
+			return fmt.Errorf("unknown worker %q", action.Worker)
+		}
+	}
+	return nil
+}
+
+func parseDelegateCalls(calls []toolCall) ([]DelegateAction, error) {
+	var actions []DelegateAction
+	for _, call := range calls {
+		args := []byte(call.Function.Arguments)
+
+		var batch DelegateBatch
+		if err := json.Unmarshal(args, &batch); err == nil && len(batch.Tasks) > 0 {
+			if err = v.Struct(batch); err != nil {
+				return nil, err
+			}
+			actions = append(actions, batch.Tasks...)
+			continue
+		}
+
+		var action DelegateAction
+		if err := json.Unmarshal(args, &action); err != nil {
+			return nil, err
+		}
+		if err := v.Struct(action); err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
 func (mc *LLMClient) GenerateSummary(ctx context.Context, task string, history []storage.Record) (string, error) {
 	content := "Here is the task:\n" + task + "\n"
 
@@ -173,40 +314,100 @@ func (mc *LLMClient) GenerateSummary(ctx context.Context, task string, history [
 	return response.Choices[0].Message.Content, nil
 }
 
+// Process runs the member as an agent: the model calls tools, reads their results and calls
+// again until it answers without tool calls. After maxRounds rounds of tools it must answer.
 func (mc *LLMClient) Process(ctx context.Context, memberKey string, audit *log.Logger, messages []Message,
-	toolkit map[string]tools.Tool, taskID string, stepID int) (string, error) {
-	toolChoice := AutoToolChoice
-	temp, maxTokens := 0.15, -1
-	response, err := mc.generateResponse(ctx, messages, toolkit, temp, maxTokens, toolChoice)
-	if err != nil {
-		return "", err
+	toolkit map[string]tools.Tool, taskID string, stepID int, maxRounds int) (string, error) {
+	if maxRounds <= 0 {
+		maxRounds = defaultMaxToolRounds
 	}
+	temp, maxTokens := 0.15, -1
+	for round := 1; ; round++ {
+		toolChoice := AutoToolChoice
+		if round > maxRounds {
+			audit.Printf("⚠️ %s reached %d tool rounds on step %d, asking for an answer", memberKey, maxRounds, stepID)
+			toolChoice = NoneToolChoice
+		}
+		response, err := mc.generateResponse(ctx, messages, toolkit, temp, maxTokens, toolChoice)
+		if err != nil {
+			return "", err
+		}
+		if len(response.Choices) == 0 {
+			return "", errors.New("empty LLM response")
+		}
 
-	message := response.Choices[0].Message
-	toolChoice = NoneToolChoice
-	newMessages := mc.handleToolCalls(ctx, audit, toolkit, message.ToolCalls, taskID, stepID, memberKey)
-	messages = append(messages, newMessages...)
-	if response, err = mc.generateResponse(ctx, messages, toolkit, temp, maxTokens, toolChoice); err != nil {
-		return "", err
+		message := response.Choices[0].Message
+		if len(message.ToolCalls) == 0 || toolChoice == NoneToolChoice {
+			return mc.saveAnswer(ctx, memberKey, taskID, stepID, message.Content), nil
+		}
+
+		mc.saveToolRound(ctx, audit, memberKey, taskID, stepID, round, message)
+		newMessages := mc.handleToolCalls(ctx, audit, toolkit, message.ToolCalls, taskID, stepID, memberKey)
+		if issue, ok := reportedIssue(message.ToolCalls, newMessages); ok {
+			audit.Printf("🚩 %s ended step %d with an issue", memberKey, stepID)
+			return issue, nil
+		}
+		messages = append(messages, newMessages...)
 	}
-	message = response.Choices[0].Message
+}
 
-	if len(message.Content) != 0 {
-		if err = mc.storage.SaveHistory(ctx, storage.Record{
-			TaskID:    taskID,
-			MemberID:  memberKey,
-			SubTaskID: int64(stepID),
-			Role:      AssistantRole,
-			Content:   message.Content,
-			CreatedAt: time.Now(),
-		}); err != nil {
-			log.Printf("⚠️ Error saving history for task %s: %v", taskID, err)
+// reportedIssue returns the result of the report_issue call of the round, the
+// worker hands the step back to the leader when it reports an issue.
+func reportedIssue(calls []toolCall, results []Message) (string, bool) {
+	for _, call := range calls {
+		if call.Function.Name != tools.ReportIssueTool {
+			continue
+		}
+		for _, result := range results {
+			if result.ToolCallID == call.ID {
+				return result.Content, true
+			}
 		}
+		return "Issue reported to the leader", true
+	}
+	return "", false
+}
 
-		return message.Content, nil
+func (mc *LLMClient) saveAnswer(ctx context.Context, memberKey, taskID string, stepID int, content string) string {
+	if len(content) == 0 {
+		return "Successfully processed"
 	}
+	if err := mc.storage.SaveHistory(ctx, storage.Record{
+		TaskID:    taskID,
+		MemberID:  memberKey,
+		SubTaskID: int64(stepID),
+		Role:      AssistantRole,
+		Content:   content,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		log.Printf("⚠️ Error saving history for task %s: %v", taskID, err)
+	}
+	return content
+}
 
-	return "Successfully processed", nil
+// saveToolRound records which tools the member called on the round, so the history
+// shows the whole chain of calls and not only the results.
+func (mc *LLMClient) saveToolRound(ctx context.Context, audit *log.Logger, memberKey, taskID string, stepID, round int,
+	message Message) {
+	names := make([]string, 0, len(message.ToolCalls))
+	for _, call := range message.ToolCalls {
+		names = append(names, call.Function.Name)
+	}
+	content := fmt.Sprintf("Round %d: calling %s", round, strings.Join(names, ", "))
+	if message.Content != "" {
+		content += "\n" + message.Content
+	}
+	audit.Printf("🔧 %s", content)
+	if err := mc.storage.SaveHistory(ctx, storage.Record{
+		TaskID:    taskID,
+		MemberID:  memberKey,
+		SubTaskID: int64(stepID),
+		Role:      AssistantRole,
+		Content:   content,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		audit.Printf("⚠️ Error saving tool round %d of task %s: %v", round, taskID, err)
+	}
 }
 
 func (mc *LLMClient) handleToolCalls(ctx context.Context, audit *log.Logger, toolkit map[string]tools.Tool,
@@ -215,7 +416,7 @@ func (mc *LLMClient) handleToolCalls(ctx context.Context, audit *log.Logger, too
 
 	for _, call := range toolCalls {
 		audit.Printf("▶️ Executing: %v", call)
-		toolTask := tools.ToolTask{Key: call.Function.Name}
+		toolTask := tools.ToolTask{Key: call.Function.Name, TaskID: taskID, StepID: stepID, MemberKey: memberKey}
 		toolTask.Parameters, _ = utils.ParseArguments(call.Function.Arguments)
 		tool, exists := toolkit[toolTask.Key]
 		if !exists || tool.HandlerFunc == nil {
@@ -268,18 +469,25 @@ func (mc *LLMClient) handleToolCalls(ctx context.Context, audit *log.Logger, too
 	return messages
 }
 
-func (mc *LLMClient) generateResponse(ctx context.Context, messages []Message, tools map[string]tools.Tool,
-	temp float64, maxTokens int, toolChoice any) (*ResponseLLM, error) {
-	messagesCurated := make([]Message, 0, len(messages))
+// curateMessages drops the empty messages, an assistant message with tool calls
+// is kept since the tool results that follow it answer its calls.
+func curateMessages(messages []Message) ([]Message, bool) {
+	curated := make([]Message, 0, len(messages))
 	hasUserPrompt := false
 	for _, msg := range messages {
-		if len(msg.Content) > 0 {
-			messagesCurated = append(messagesCurated, msg)
+		if len(msg.Content) > 0 || len(msg.ToolCalls) > 0 {
+			curated = append(curated, msg)
 		}
 		if msg.Role == UserRole {
 			hasUserPrompt = true
 		}
 	}
+	return curated, hasUserPrompt
+}
+
+func (mc *LLMClient) generateResponse(ctx context.Context, messages []Message, tools map[string]tools.Tool,
+	temp float64, maxTokens int, toolChoice any) (*ResponseLLM, error) {
+	messagesCurated, hasUserPrompt := curateMessages(messages)
 	if !hasUserPrompt {
 		return nil, errors.New("no user prompt found")
 	}
@@ -288,8 +496,9 @@ func (mc *LLMClient) generateResponse(ctx context.Context, messages []Message, t
 		Model:       mc.model,
 		Tools:       functionsToPayload(tools),
 		Messages:    messagesCurated,
-		Temperature: temp,
-		MaxTokens:   maxTokens,
+		Temperature: mc.settings.temperature(temp),
+		MaxTokens:   mc.settings.maxTokens(maxTokens),
+		Seed:        mc.settings.Seed,
 		ToolChoice:  toolChoice,
 	}
 
@@ -316,9 +525,10 @@ func (mc *LLMClient) sendRequestAndParse(ctx context.Context, payload requestPay
 			log.Printf("⚠️ HTTP400 LLM request failed: request %v", payload)
 		}
 		var out ResponseLLM
-		if uErr := json.Unmarshal(respBytes, &out); uErr != nil {
-			err = fmt.Errorf("unmarshal: %w", uErr)
+		if err = json.Unmarshal(respBytes, &out); err != nil {
+			return nil, fmt.Errorf("unmarshal LLM response: %w", err)
 		}
+		UsageFromContext(ctx).add(&out)
 		return &out, nil
 	}
 	return nil, fmt.Errorf("request failed: %w", err)
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoWorkerAI/app/utils/restclient"
+)
+
+func TestCheckWorkers(t *testing.T) {
+	workers := []string{"coder", "tester"}
+
+	assert.NoError(t, checkWorkers([]DelegateAction{
+		{Worker: "coder", Task: "write the handler"},
+		{Worker: "none", Task: "finish"},
+	}, workers))
+	assert.EqualError(t, checkWorkers([]DelegateAction{
+		{Worker: "coder", Task: "write the handler"},
+		{Worker: "worker_1", Task: "write the tests"},
+	}, workers), `unknown worker "worker_1"`)
+	assert.NoError(t, checkWorkers([]DelegateAction{{Worker: "anyone", Task: "a task"}}, nil))
+}
+
+func TestCurateMessagesKeepsToolCalls(t *testing.T) {
+	calls := []toolCall{{ID: "call_1", Type: "function", Function: toolFunction{Name: "delegate_task"}}}
+	curated, hasUserPrompt := curateMessages([]Message{
+		{Role: SystemRole, Content: "system"},
+		{Role: UserRole, Content: "task"},
+		{Role: AssistantRole, ToolCalls: calls},
+		{Role: ToolRole, Content: "invalid worker", ToolCallID: "call_1"},
+		{Role: AssistantRole},
+	})
+	assert.True(t, hasUserPrompt)
+	assert.Len(t, curated, 4)
+	assert.Equal(t, calls, curated[2].ToolCalls)
+	assert.Equal(t, "call_1", curated[3].ToolCallID)
+}
+
+func TestThinkMalformedResponses(t *testing.T) {
+	body := "not json"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := newLLMClient(nil, "test", "", restclient.NewRestClient(server.URL, nil))
+	messages := CreateMessages("hi", "system")
+
+	_, err := client.Think(context.Background(), messages, 0.1, -1)
+	assert.ErrorContains(t, err, "unmarshal")
+
+	body = `{"choices":[]}`
+	_, err = client.Think(context.Background(), messages, 0.1, -1)
+	assert.ErrorContains(t, err, "empty LLM response")
+}
@@ -49,6 +49,7 @@ type requestPayload struct {
 	Messages    []Message         `json:"messages"`
 	Temperature float64           `json:"temperature"`
 	MaxTokens   int               `json:"max_tokens"`
+	Seed        *int              `json:"seed,omitempty"`
 	Tools       []functionPayload `json:"tools"`
 	ToolChoice  any               `json:"tool_choice,omitempty"` // "auto" | "none" | ToolChoiceFunction
 }
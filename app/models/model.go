@@ -17,11 +17,13 @@ const (
 
 type Interface interface {
 	Think(context.Context, []Message, float64, int) (string, error)
-	Process(context.Context, string, *log.Logger, []Message, map[string]tools.Tool, string, int) (string, error)
-	Delegate(context.Context, string, string, string) (*DelegateAction, error)
+	Process(context.Context, string, *log.Logger, []Message, map[string]tools.Tool, string, int, int) (string, error)
+	Delegate(context.Context, string, string, string, []string) ([]DelegateAction, error)
 	TrueOrFalse(context.Context, []Message) (bool, string, error)
 	GenerateSummary(context.Context, string, []storage.Record) (string, error)
 	EmbedText(context.Context, string) ([]float32, error)
+	TokenEstimator() TokenEstimator
+	WithSettings(Settings) Interface
 }
 
 type Message struct {
@@ -32,11 +34,20 @@ type Message struct {
 }
 
 type DelegateAction struct {
-	Worker  string `json:"worker,validate:required"`
-	Task    string `json:"task,validate:required"`
+	Worker  string `json:"worker" validate:"required"`
+	Task    string `json:"task" validate:"required"`
+	Step    int    `json:"step,omitempty"`
 	Context string `json:"context"`
 }
 
+type DelegateBatch struct {
+	Tasks []DelegateAction `json:"tasks" validate:"required,min=1,dive"`
+}
+
+func (a DelegateAction) IsFinish() bool {
+	return a.Worker == tools.NoWorker && a.Task == "finish"
+}
+
 func CreateMessages(userPrompt, sysPrompt string) []Message {
 	return []Message{
 		{Role: SystemRole, Content: sysPrompt},
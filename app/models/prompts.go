@@ -23,6 +23,49 @@ HARD OUTPUT FORMAT:
 - Exactly one line per step. No text before, between, or after steps.
 `
 
+const ReplanSystemPrompt = `
+You are an expert strategic planner. The current plan of the task described below is not working.
+You will receive the task, the plan with the status of every step, the execution summary and the problem found.
+
+OBJECTIVES:
+- Plan ONLY the remaining work, the steps marked done are kept as they are.
+- Work around the problem found, do not repeat a failing step unchanged.
+- Steps must be actionable, testable, and as small as reasonably possible.
+
+HARD OUTPUT FORMAT:
+- Output ONLY a numbered list of the remaining steps.
+- Format exactly as:
+  1. [First remaining step]
+  2. [Next step]
+  ...
+  N. [Final step]
+- Start at 1 and increment by 1.
+- Exactly one line per step. No text before, between, or after steps.
+`
+
+const ReplanContextPrompt = "Task:\n%s\n\nCurrent plan:\n%s\n\nExecution summary:\n%s\n\nProblem found:\n%s"
+
+const UserInstructionsPrompt = "\nInstructions from the user, they take precedence over the task description and the plan:\n%s"
+
+const IssuesPrompt = "\nIssues reported by the workers. Do not delegate work that depends on a BLOCKING issue, " +
+	"work around it or finish the task explaining what is missing. Follow the answers given by humans:\n%s"
+
+const ReviewSystemPrompt = `
+Instructions:
+- You are reviewing the work of a team member on a single subtask.
+- Analyze the subtask, the answer of the member and the tool calls it made.
+- Respond ONLY using the "true_or_false" tool.
+- "true" means the work fulfills the subtask and is correct.
+- "false" means something is missing, incorrect, or incomplete.
+
+When rejecting, the reason must tell the member exactly what to fix.
+No explanations or extra text outside the tool call.
+`
+
+const ReviewContextPrompt = "Subtask:\n%s\n\nMember answer:\n%s\n\nTool calls of the member:%s"
+
+const RevisionPrompt = "Your previous work on this subtask was rejected by the reviewer.\nYour previous answer:\n%s\n\nReason: %s\n\nFix what the reviewer rejected, keep the rest of your work. Subtask:\n%s"
+
 const TaskDoneBoolPrompt = `
 Instructions:
 - Analyze the provided task description, plan, and execution summary.
@@ -48,3 +91,44 @@ const SummarySystemPrompt = `You will receive the task to be completed and a fla
 	"[Description of the first entry]\n[Description of the next entry]\n...\n[Final entry]\n".`
 
 const SummaryContextPrompt = `The task to complete is:\n%s\n\nHere is the task history logs:%s`
+
+const DryRunSystemPrompt = `You simulate the execution of a tool for a dry run, nothing is executed for real.
+Reply ONLY with the output the tool would most likely return for the call, without explanations.
+Keep it short and plausible, prefer a successful result.`
+
+const DryRunToolPrompt = "Tool: %s\nDescription: %s\nArguments: %s"
+
+const CompactSummarySystemPrompt = `You will receive the timeline of a task execution, one entry per line.
+Rewrite it shorter, in at most %d tokens:
+- Merge related entries and drop the ones that no longer matter for the task.
+- Keep the created files, the decisions, the results and the problems still open.
+- Keep the chronological order and the format, one past-tense entry per line, no text before or after.`
+
+const ToolOutputSummarySystemPrompt = `You will receive the output of a tool call that is too long to keep.
+Summarize it in at most %d tokens for the worker that made the call:
+- Keep the errors, the results, names, paths, numbers and every detail needed to continue the work.
+- Quote short relevant fragments exactly.
+- Output only the summary.`
+
+const ToolOutputSummaryPrompt = "Tool: %s\nArguments: %s\nOutput:\n%s"
+
+const SessionSummarySystemPrompt = `You will receive the summary of a conversation so far and its next messages.
+Rewrite the summary in at most %d tokens, including the new messages:
+- Keep who the user is, what they asked, the answers given and anything still pending.
+- Keep names, numbers and decisions exactly.
+- Output only the summary.`
+
+const SessionSummaryPrompt = "Summary so far:\n%s\n\nNext messages:\n%s"
+
+const SessionContextPrompt = "\nSummary of the earlier conversation:\n%s\n\nLatest messages:\n%s"
+
+const EventHandlerToolsPrompt = `
+TOOLS: you can act on the tasks of the teams with your tools. Use them only when the user clearly asks to create,
+cancel, inspect or change a task, otherwise just answer. Never guess a task ID, ask for it or look it up with
+get_task_status. After a tool call, tell the user what you did and the task ID.`
+
+const DelegateCorrectionPrompt = `ERROR: %v. Call the tool again with a worker from this list: %s, or "%s".`
+
+const DelegateNoCallPrompt = `You must answer by calling delegate_task or delegate_tasks.`
+
+const UnfinishedPrompt = "\nThe task is not finished yet, the last completion check says:\n%s"
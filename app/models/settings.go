@@ -0,0 +1,69 @@
+package models
+
+import "fmt"
+
+// Settings overrides the model, the endpoint and the sampling used for the
+// calls of a team or a member, the unset fields keep the defaults.
+type Settings struct {
+	Model   string `yaml:"model,omitempty"`
+	BaseURL string `yaml:"base_url,omitempty"`
+	APIKey  string `yaml:"api_key,omitempty"`
+	// Temperature replaces the temperature each call picks for its purpose.
+	Temperature *float64 `yaml:"temperature,omitempty"`
+	// MaxTokens caps the tokens of every answer.
+	MaxTokens int  `yaml:"max_tokens,omitempty"`
+	Seed      *int `yaml:"seed,omitempty"`
+}
+
+func (s Settings) IsZero() bool {
+	return s.Model == "" && s.BaseURL == "" && s.APIKey == "" && s.Temperature == nil && s.MaxTokens == 0 &&
+		s.Seed == nil
+}
+
+// Merge returns the settings with the fields set in override replaced.
+func (s Settings) Merge(override Settings) Settings {
+	if override.Model != "" {
+		s.Model = override.Model
+	}
+	if override.BaseURL != "" {
+		s.BaseURL = override.BaseURL
+	}
+	if override.APIKey != "" {
+		s.APIKey = override.APIKey
+	}
+	if override.Temperature != nil {
+		s.Temperature = override.Temperature
+	}
+	if override.MaxTokens != 0 {
+		s.MaxTokens = override.MaxTokens
+	}
+	if override.Seed != nil {
+		s.Seed = override.Seed
+	}
+	return s
+}
+
+func (s Settings) Validate() error {
+	if s.Temperature != nil && (*s.Temperature < 0 || *s.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+	if s.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens cannot be negative")
+	}
+	return nil
+}
+
+func (s Settings) temperature(temp float64) float64 {
+	if s.Temperature != nil {
+		return *s.Temperature
+	}
+	return temp
+}
+
+// maxTokens caps the tokens of the call, -1 asks for no limit.
+func (s Settings) maxTokens(maxTokens int) int {
+	if s.MaxTokens > 0 && (maxTokens <= 0 || maxTokens > s.MaxTokens) {
+		return s.MaxTokens
+	}
+	return maxTokens
+}
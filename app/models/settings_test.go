@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettingsMerge(t *testing.T) {
+	low, high, seed := 0.1, 0.7, 42
+	team := Settings{Model: "big", Temperature: &low, MaxTokens: 2000}
+	member := team.Merge(Settings{Model: "small", Temperature: &high, Seed: &seed})
+
+	assert.Equal(t, "small", member.Model)
+	assert.Equal(t, 0.7, member.temperature(0.15))
+	assert.Equal(t, 2000, member.MaxTokens)
+	assert.Equal(t, &seed, member.Seed)
+	assert.Equal(t, team, team.Merge(Settings{}))
+	assert.True(t, Settings{}.IsZero())
+}
+
+func TestSettingsSampling(t *testing.T) {
+	assert.Equal(t, 0.15, Settings{}.temperature(0.15))
+	assert.Equal(t, -1, Settings{}.maxTokens(-1))
+	assert.Equal(t, 500, Settings{MaxTokens: 500}.maxTokens(-1))
+	assert.Equal(t, 500, Settings{MaxTokens: 500}.maxTokens(3850))
+	assert.Equal(t, 100, Settings{MaxTokens: 500}.maxTokens(100))
+
+	hot := 2.5
+	assert.Error(t, Settings{Temperature: &hot}.Validate())
+	assert.Error(t, Settings{MaxTokens: -1}.Validate())
+	assert.NoError(t, Settings{}.Validate())
+}
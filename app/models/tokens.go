@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+const defaultCharsPerToken = 4.0
+
+// charsPerToken is the average number of characters per token of each model
+// family, close enough to budget the prompts without loading the tokenizer.
+var charsPerToken = []struct {
+	family string
+	ratio  float64
+}{
+	{"qwen", 3.6},
+	{"llama", 3.8},
+	{"mistral", 3.5},
+	{"mixtral", 3.5},
+	{"gemma", 3.8},
+	{"phi", 3.6},
+	{"deepseek", 3.7},
+	{"gpt", 4.0},
+}
+
+// TokenEstimator counts the tokens of a text for a model.
+type TokenEstimator struct {
+	ratio float64
+}
+
+func NewTokenEstimator(model string) TokenEstimator {
+	model = strings.ToLower(model)
+	for _, family := range charsPerToken {
+		if strings.Contains(model, family.family) {
+			return TokenEstimator{ratio: family.ratio}
+		}
+	}
+	return TokenEstimator{ratio: defaultCharsPerToken}
+}
+
+func (e TokenEstimator) Count(text string) int {
+	ratio := e.ratio
+	if ratio <= 0 {
+		ratio = defaultCharsPerToken
+	}
+	return int(math.Ceil(float64(utf8.RuneCountInString(text)) / ratio))
+}
+
+// Truncate keeps the start and the end of a text longer than tokens, the end
+// of a tool output often holds the error or the result.
+func (e TokenEstimator) Truncate(text string, tokens int) string {
+	total := e.Count(text)
+	if total <= tokens {
+		return text
+	}
+	runes := []rune(text)
+	keep := int(float64(len(runes)) * float64(tokens) / float64(total))
+	head := keep * 2 / 3
+	tail := keep - head
+	return fmt.Sprintf("%s\n[... %d tokens truncated ...]\n%s",
+		string(runes[:head]), total-tokens, string(runes[len(runes)-tail:]))
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenEstimatorCount(t *testing.T) {
+	assert.Equal(t, 0, NewTokenEstimator("unknown").Count(""))
+	assert.Equal(t, 3, NewTokenEstimator("unknown").Count("0123456789"))
+	assert.Equal(t, 10, NewTokenEstimator("qwen2.5-coder").Count(strings.Repeat("a", 36)))
+	assert.Equal(t, 2, TokenEstimator{}.Count("12345678"))
+}
+
+func TestTokenEstimatorTruncate(t *testing.T) {
+	e := NewTokenEstimator("gpt")
+	assert.Equal(t, "short", e.Truncate("short", 10))
+
+	text := strings.Repeat("a", 400) + strings.Repeat("z", 400)
+	truncated := e.Truncate(text, 50)
+	assert.True(t, strings.HasPrefix(truncated, "aaaa"))
+	assert.True(t, strings.HasSuffix(truncated, "zzzz"))
+	assert.Contains(t, truncated, "[... 150 tokens truncated ...]")
+	assert.Less(t, e.Count(truncated), 70)
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type usageKey struct{}
+
+// Usage accumulates the tokens reported by the LLM for every request made
+// with a context returned by WithUsage. The tokens are also added to the usage
+// of the parent context, so a task accounts for the work of its sub-tasks.
+type Usage struct {
+	promptTokens     atomic.Int64
+	completionTokens atomic.Int64
+	totalTokens      atomic.Int64
+	parent           *Usage
+}
+
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	usage := &Usage{parent: UsageFromContext(ctx)}
+	return context.WithValue(ctx, usageKey{}, usage), usage
+}
+
+func UsageFromContext(ctx context.Context) *Usage {
+	usage, _ := ctx.Value(usageKey{}).(*Usage)
+	return usage
+}
+
+func (u *Usage) add(response *ResponseLLM) {
+	if response == nil {
+		return
+	}
+	for ; u != nil; u = u.parent {
+		u.promptTokens.Add(int64(response.Usage.PromptTokens))
+		u.completionTokens.Add(int64(response.Usage.CompletionTokens))
+		u.totalTokens.Add(int64(response.Usage.TotalTokens))
+	}
+}
+
+func (u *Usage) PromptTokens() int64 {
+	return u.promptTokens.Load()
+}
+
+func (u *Usage) CompletionTokens() int64 {
+	return u.completionTokens.Load()
+}
+
+func (u *Usage) TotalTokens() int64 {
+	return u.totalTokens.Load()
+}
@@ -0,0 +1,152 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/tools"
+)
+
+const ApprovalRole = "approval"
+
+type Approval struct {
+	ID          string
+	TaskID      string
+	StepID      int
+	Member      string
+	Tool        string
+	Arguments   string
+	RequestedAt time.Time
+	decision    chan ApprovalDecision
+}
+
+type ApprovalDecision struct {
+	Approved bool
+	Approver string
+	Reason   string
+}
+
+func (d ApprovalDecision) String() string {
+	verdict := "rejected"
+	if d.Approved {
+		verdict = "approved"
+	}
+	text := fmt.Sprintf("%s by %s", verdict, d.Approver)
+	if d.Reason != "" {
+		text += ": " + d.Reason
+	}
+	return text
+}
+
+// approvalGate wraps the tool so every call waits for a human decision, a
+// rejection is returned to the model as the tool result.
+func (r *Runtime) approvalGate(ctx context.Context, task *teams.Task, tool tools.Tool) tools.Tool {
+	handler := tool.HandlerFunc
+	if handler == nil {
+		return tool
+	}
+	tool.HandlerFunc = func(toolTask tools.ToolTask) (string, error) {
+		decision := r.requestApproval(ctx, task, tool.Name, toolTask)
+		if !decision.Approved {
+			return fmt.Sprintf("REJECTED: the call to %s was %s. Do not retry the same call.", tool.Name, decision), nil
+		}
+		return handler(toolTask)
+	}
+	return tool
+}
+
+func (r *Runtime) requestApproval(ctx context.Context, task *teams.Task, toolName string,
+	toolTask tools.ToolTask) ApprovalDecision {
+	arguments, _ := json.Marshal(toolTask.Parameters)
+	approval := &Approval{
+		ID:          uuid.New().String()[:8],
+		TaskID:      task.ID.String(),
+		StepID:      toolTask.StepID,
+		Member:      toolTask.MemberKey,
+		Tool:        toolName,
+		Arguments:   string(arguments),
+		RequestedAt: time.Now(),
+		decision:    make(chan ApprovalDecision, 1),
+	}
+
+	r.mu.Lock()
+	r.approvals[approval.ID] = approval
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.approvals, approval.ID)
+		r.mu.Unlock()
+	}()
+
+	r.publish(task, ApprovalRequired, ApprovalRequiredPayload{Approval: *approval})
+
+	var timeout <-chan time.Time
+	if r.config.ApprovalTimeout > 0 {
+		timer := time.NewTimer(r.config.ApprovalTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var decision ApprovalDecision
+	select {
+	case decision = <-approval.decision:
+	case <-timeout:
+		decision = ApprovalDecision{Approver: runtimeMemberKey, Reason: "no answer before the approval timeout"}
+	case <-ctx.Done():
+		decision = ApprovalDecision{Approver: runtimeMemberKey, Reason: "the task stopped"}
+	}
+
+	if err := r.db.SaveHistory(context.Background(), storage.Record{
+		TaskID:     approval.TaskID,
+		SubTaskID:  int64(approval.StepID),
+		MemberID:   approval.Member,
+		Role:       ApprovalRole,
+		Tool:       approval.Tool,
+		Parameters: approval.Arguments,
+		Content:    decision.String(),
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		log.Printf("⚠️ Error saving approval %s: %v", approval.ID, err)
+	}
+	log.Printf("🔐 Approval %s for %s %s", approval.ID, approval.Tool, decision)
+	return decision
+}
+
+// ResolveApproval delivers the decision of a human to the tool call waiting for it.
+func (r *Runtime) ResolveApproval(id string, decision ApprovalDecision) error {
+	r.mu.RLock()
+	approval, ok := r.approvals[id]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("approval %s not found or already resolved", id)
+	}
+
+	select {
+	case approval.decision <- decision:
+		return nil
+	default:
+		return fmt.Errorf("approval %s already resolved", id)
+	}
+}
+
+func (r *Runtime) PendingApprovals() []Approval {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	approvals := make([]Approval, 0, len(r.approvals))
+	for _, approval := range r.approvals {
+		approvals = append(approvals, *approval)
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		return approvals[i].RequestedAt.Before(approvals[j].RequestedAt)
+	})
+	return approvals
+}
@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+)
+
+const runtimeMemberKey = "runtime"
+
+var ErrBudgetExhausted = errors.New(storage.TaskBudgetOut)
+
+type budgetTracker struct {
+	budget   teams.Budget
+	usage    *models.Usage
+	started  time.Time
+	failures int
+}
+
+func newBudgetTracker(team *teams.Team, task *teams.Task, usage *models.Usage) *budgetTracker {
+	return &budgetTracker{
+		budget:  team.Budget.Merge(task.Budget).WithDefaults(),
+		usage:   usage,
+		started: time.Now(),
+	}
+}
+
+func (b *budgetTracker) fail() {
+	b.failures++
+}
+
+func (b *budgetTracker) succeed() {
+	b.failures = 0
+}
+
+// withDeadline bounds the context by the duration budget, so a long model call
+// is interrupted as well.
+func (b *budgetTracker) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.budget.MaxDuration <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, b.started.Add(b.budget.MaxDuration))
+}
+
+// remainingSteps returns how many steps can still run, -1 means unlimited.
+func (b *budgetTracker) remainingSteps(steps int) int {
+	if b.budget.MaxSteps <= 0 {
+		return -1
+	}
+	return max(b.budget.MaxSteps-steps, 0)
+}
+
+// check returns an error wrapping ErrBudgetExhausted once any limit is reached.
+func (b *budgetTracker) check(steps int) error {
+	switch {
+	case b.budget.MaxSteps > 0 && steps >= b.budget.MaxSteps:
+		return fmt.Errorf("%w: reached the limit of %d steps", ErrBudgetExhausted, b.budget.MaxSteps)
+	case b.budget.MaxConsecutiveFailures > 0 && b.failures >= b.budget.MaxConsecutiveFailures:
+		return fmt.Errorf("%w: %d consecutive failures", ErrBudgetExhausted, b.failures)
+	case b.budget.MaxTokens > 0 && b.usage != nil && b.usage.TotalTokens() >= int64(b.budget.MaxTokens):
+		return fmt.Errorf("%w: used %d of %d tokens", ErrBudgetExhausted, b.usage.TotalTokens(), b.budget.MaxTokens)
+	case b.budget.MaxDuration > 0 && time.Since(b.started) >= b.budget.MaxDuration:
+		return fmt.Errorf("%w: ran for more than %s", ErrBudgetExhausted, b.budget.MaxDuration)
+	}
+	return nil
+}
+
+// exhaustBudget records the terminal status in the task history, clients are
+// told through the task_failed event.
+func (r *Runtime) exhaustBudget(team *teams.Team, cause error) {
+	task := team.Task
+	team.Audits.Printf("⛔ Task %s stopped: %v", task.ID.String(), cause)
+
+	if err := r.db.SaveHistory(context.Background(), storage.Record{
+		TaskID:    task.ID.String(),
+		MemberID:  runtimeMemberKey,
+		Role:      models.SystemRole,
+		Content:   cause.Error(),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		log.Printf("⚠️ Error saving history for task %s: %v", task.ID.String(), err)
+	}
+}
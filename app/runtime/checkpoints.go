@@ -0,0 +1,79 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+)
+
+const resumeHistory = 10
+
+// loadCheckpoint returns the last checkpoint of the task, if any, along with the
+// latest records of its history so the leader knows what was done before.
+func (r *Runtime) loadCheckpoint(ctx context.Context, task *teams.Task) (*storage.Checkpoint, []storage.Record, error) {
+	cp, err := r.db.GetCheckpoint(ctx, task.ID.String())
+	if err != nil || cp == nil {
+		return nil, nil, err
+	}
+
+	history, err := r.db.GetHistoryByTaskID(ctx, task.ID.String(), -1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(history) > resumeHistory {
+		history = history[len(history)-resumeHistory:]
+	}
+	return cp, history, nil
+}
+
+func (r *Runtime) saveCheckpoint(ctx context.Context, cp *storage.Checkpoint, plan teams.Plan) {
+	cp.Plan = plan.Encode()
+	if err := r.db.SaveCheckpoint(ctx, *cp); err != nil {
+		log.Printf("⚠️ Error saving checkpoint for task %s: %v", cp.TaskID, err)
+	}
+}
+
+// ResumeTask lets a paused task continue, or puts an unfinished task back in
+// the queue to continue from its last checkpoint once it is picked up.
+func (r *Runtime) ResumeTask(ctx context.Context, taskID string) error {
+	r.mu.RLock()
+	_, running := r.running[taskID]
+	r.mu.RUnlock()
+	if running {
+		if r.unpauseTask(ctx, taskID) {
+			return nil
+		}
+		return fmt.Errorf("task %s is already running", taskID)
+	}
+
+	queued, err := r.db.GetQueuedTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if queued == nil || queued.Team != r.team.Name {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	if queued.ParentID != "" {
+		return fmt.Errorf("task %s is a sub-task, resume its parent %s", taskID, queued.ParentID)
+	}
+	switch queued.Status {
+	case storage.TaskCompleted:
+		return fmt.Errorf("task %s is already completed", taskID)
+	case storage.TaskPending:
+		return fmt.Errorf("task %s is already queued", taskID)
+	case storage.TaskRunning:
+		return fmt.Errorf("task %s is already running", taskID)
+	case storage.TaskBudgetOut:
+		// The checkpoint keeps the spent budget, the task would stop again right away.
+		return fmt.Errorf("task %s exhausted its budget, create a new task with a larger one", taskID)
+	}
+
+	if err = r.db.UpdateTaskStatus(ctx, taskID, storage.TaskPending); err != nil {
+		return err
+	}
+	r.wake()
+	return nil
+}
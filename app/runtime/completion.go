@@ -0,0 +1,177 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/teams"
+)
+
+// completionOutput is how much of the end of a failed verification command is kept in the reason.
+const completionOutput = 1000
+
+// CompletionPolicy decides whether the task is finished after a step, with the reason of the decision.
+type CompletionPolicy interface {
+	Complete(ctx context.Context, check CompletionCheck) (bool, string, error)
+}
+
+// CompletionCheck is the state of the task a policy decides on.
+type CompletionCheck struct {
+	Team    *teams.Team
+	Plan    teams.Plan
+	Summary string
+}
+
+// completionPolicy builds the policy configured for the team, the leader model judges by default.
+func (r *Runtime) completionPolicy(config *teams.Completion) CompletionPolicy {
+	if config == nil {
+		return modelJudge{r: r}
+	}
+	switch config.Type {
+	case teams.CompletionCommand, teams.CompletionFiles:
+		if r.config.DryRun.Enabled {
+			// Nothing is written in a dry run, so the leader judges the simulated work instead.
+			return modelJudge{r: r}
+		}
+	}
+	switch config.Type {
+	case teams.CompletionReviewer:
+		return modelJudge{r: r, reviewer: true}
+	case teams.CompletionCommand:
+		return commandCheck{dir: r.config.Workspace, command: config.Command, timeout: config.CommandTimeout()}
+	case teams.CompletionFiles:
+		return filesCheck{dir: r.config.Workspace, patterns: config.Files, match: regexp.MustCompile(config.Match)}
+	case teams.CompletionAll, teams.CompletionAny:
+		policies := make([]CompletionPolicy, 0, len(config.Policies))
+		for i := range config.Policies {
+			policies = append(policies, r.completionPolicy(&config.Policies[i]))
+		}
+		if config.Type == teams.CompletionAll {
+			return allPolicies(policies)
+		}
+		return anyPolicy(policies)
+	}
+	return modelJudge{r: r}
+}
+
+// modelJudge asks the leader, or the reviewer, whether the summary shows the task is finished.
+type modelJudge struct {
+	r        *Runtime
+	reviewer bool
+}
+
+func (p modelJudge) Complete(ctx context.Context, check CompletionCheck) (bool, string, error) {
+	judge := check.Team.GetLeader()
+	if p.reviewer {
+		judge = check.Team.GetReviewer()
+	}
+	if judge == nil {
+		return false, "", fmt.Errorf("the team has no member to judge the completion")
+	}
+	messages := models.CreateMessages(fmt.Sprintf("Task : %s\n Summary: %s", check.Plan.String(), check.Summary),
+		judge.Prompt(models.TaskDoneBoolPrompt))
+	return p.r.modelFor(judge).TrueOrFalse(ctx, messages)
+}
+
+// commandCheck runs a verification command in the workspace, e.g. the tests.
+type commandCheck struct {
+	dir     string
+	command string
+	timeout time.Duration
+}
+
+func (p commandCheck) Complete(ctx context.Context, _ CompletionCheck) (bool, string, error) {
+	cmdCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, "sh", "-c", p.command)
+	cmd.Dir = p.dir
+	// Children of the shell may keep the output open after it is killed.
+	cmd.WaitDelay = time.Second
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return true, fmt.Sprintf("`%s` succeeded", p.command), nil
+	}
+	if ctx.Err() != nil {
+		return false, "", ctx.Err()
+	}
+	if cmdCtx.Err() != nil {
+		err = fmt.Errorf("timed out after %s", p.timeout)
+	}
+	text := strings.TrimSpace(string(output))
+	if len(text) > completionOutput {
+		text = "..." + text[len(text)-completionOutput:]
+	}
+	return false, fmt.Sprintf("`%s` failed: %v\n%s", p.command, err, text), nil
+}
+
+// filesCheck expects files in the workspace matching every pattern, with
+// contents matching the regular expression.
+type filesCheck struct {
+	dir      string
+	patterns []string
+	match    *regexp.Regexp
+}
+
+func (p filesCheck) Complete(_ context.Context, _ CompletionCheck) (bool, string, error) {
+	var found []string
+	for _, pattern := range p.patterns {
+		paths, err := filepath.Glob(filepath.Join(p.dir, pattern))
+		if err != nil {
+			return false, "", err
+		}
+		if len(paths) == 0 {
+			return false, fmt.Sprintf("no file matches %s", pattern), nil
+		}
+		for _, path := range paths {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return false, fmt.Sprintf("cannot read %s: %v", path, err), nil
+			}
+			if !p.match.Match(content) {
+				return false, fmt.Sprintf("%s does not match %s", path, p.match), nil
+			}
+			found = append(found, path)
+		}
+	}
+	return true, fmt.Sprintf("expected files found: %s", strings.Join(found, ", ")), nil
+}
+
+// allPolicies completes when every policy does, it stops at the first that doesn't.
+type allPolicies []CompletionPolicy
+
+func (p allPolicies) Complete(ctx context.Context, check CompletionCheck) (bool, string, error) {
+	reasons := make([]string, 0, len(p))
+	for _, policy := range p {
+		done, reason, err := policy.Complete(ctx, check)
+		if err != nil || !done {
+			return false, reason, err
+		}
+		reasons = append(reasons, reason)
+	}
+	return true, strings.Join(reasons, "; "), nil
+}
+
+// anyPolicy completes as soon as one policy does, the errors of the others count as not complete.
+type anyPolicy []CompletionPolicy
+
+func (p anyPolicy) Complete(ctx context.Context, check CompletionCheck) (bool, string, error) {
+	reasons := make([]string, 0, len(p))
+	for _, policy := range p {
+		done, reason, err := policy.Complete(ctx, check)
+		if err != nil {
+			reason = err.Error()
+		}
+		if done && err == nil {
+			return true, reason, nil
+		}
+		reasons = append(reasons, reason)
+	}
+	return false, strings.Join(reasons, "; "), nil
+}
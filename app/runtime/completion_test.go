@@ -0,0 +1,95 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedPolicy struct {
+	done   bool
+	reason string
+	err    error
+}
+
+func (p fixedPolicy) Complete(context.Context, CompletionCheck) (bool, string, error) {
+	return p.done, p.reason, p.err
+}
+
+func TestCommandCheck(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	done, reason, err := commandCheck{dir: dir, command: "true", timeout: time.Minute}.Complete(ctx, CompletionCheck{})
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, "`true` succeeded", reason)
+
+	done, reason, err = commandCheck{dir: dir, command: "echo FAIL: TestAdd; exit 1", timeout: time.Minute}.
+		Complete(ctx, CompletionCheck{})
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Contains(t, reason, "exit status 1")
+	assert.Contains(t, reason, "FAIL: TestAdd")
+
+	done, reason, err = commandCheck{dir: dir, command: "sleep 5", timeout: 50 * time.Millisecond}.
+		Complete(ctx, CompletionCheck{})
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Contains(t, reason, "timed out")
+}
+
+func TestFilesCheck(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main_test.go"), []byte("package main"), 0o644))
+
+	check := filesCheck{dir: dir, patterns: []string{"*.go"}, match: regexp.MustCompile(`package main`)}
+	done, _, err := check.Complete(context.Background(), CompletionCheck{})
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	check.patterns = []string{"*.go", "README.md"}
+	done, reason, err := check.Complete(context.Background(), CompletionCheck{})
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, "no file matches README.md", reason)
+
+	check = filesCheck{dir: dir, patterns: []string{"*_test.go"}, match: regexp.MustCompile(`func Test`)}
+	done, _, _ = check.Complete(context.Background(), CompletionCheck{})
+	assert.False(t, done)
+}
+
+func TestCombinedPolicies(t *testing.T) {
+	ctx := context.Background()
+	yes := fixedPolicy{done: true, reason: "tests pass"}
+	no := fixedPolicy{reason: "README missing"}
+	broken := fixedPolicy{err: errors.New("no reviewer")}
+
+	done, reason, err := allPolicies{yes, no}.Complete(ctx, CompletionCheck{})
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, "README missing", reason)
+
+	done, reason, _ = allPolicies{yes, yes}.Complete(ctx, CompletionCheck{})
+	assert.True(t, done)
+	assert.Equal(t, "tests pass; tests pass", reason)
+
+	_, _, err = allPolicies{yes, broken}.Complete(ctx, CompletionCheck{})
+	assert.Error(t, err)
+
+	done, reason, err = anyPolicy{broken, no, yes}.Complete(ctx, CompletionCheck{})
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, "tests pass", reason)
+
+	done, reason, _ = anyPolicy{broken, no}.Complete(ctx, CompletionCheck{})
+	assert.False(t, done)
+	assert.Equal(t, "no reviewer; README missing", reason)
+}
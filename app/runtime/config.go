@@ -0,0 +1,61 @@
+package runtime
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	QueueOrderFIFO     = "fifo"
+	QueueOrderPriority = "priority"
+)
+
+type Config struct {
+	MaxConcurrentTasks int    `yaml:"max_concurrent_tasks,omitempty"`
+	QueueOrder         string `yaml:"queue_order,omitempty"`
+	// ResumeOnStart puts the tasks interrupted by a restart back in the queue to
+	// continue from their last checkpoint, otherwise they wait for a resume_task event.
+	ResumeOnStart bool `yaml:"resume_on_start,omitempty"`
+	// ApprovalTimeout rejects a tool call waiting for approval after this long, zero waits until the task stops.
+	ApprovalTimeout time.Duration `yaml:"approval_timeout,omitempty"`
+	// DryRun simulates the tools of the workers to see what a team would do.
+	DryRun DryRun `yaml:"dry_run,omitempty"`
+	// Workspace is the folder the workers change, the files a task creates, modifies
+	// or deletes in it are listed in its result. The changes can't be told apart
+	// when tasks run at the same time, so their results list no files.
+	Workspace string `yaml:"workspace,omitempty"`
+	// Context budgets the prompts to the context window of the model.
+	Context ContextConfig `yaml:"context,omitempty"`
+	// Schedules are set from the schedules section of the config, each runtime
+	// only runs the ones that target its team.
+	Schedules []Schedule `yaml:"-"`
+}
+
+func (c Config) Validate() error {
+	if c.MaxConcurrentTasks < 0 {
+		return fmt.Errorf("max_concurrent_tasks cannot be negative")
+	}
+	if c.ApprovalTimeout < 0 {
+		return fmt.Errorf("approval_timeout cannot be negative")
+	}
+	if err := c.Context.Validate(); err != nil {
+		return err
+	}
+	switch c.QueueOrder {
+	case "", QueueOrderFIFO, QueueOrderPriority:
+	default:
+		return fmt.Errorf("unknown queue_order %q, expected %s or %s", c.QueueOrder, QueueOrderFIFO, QueueOrderPriority)
+	}
+	return nil
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxConcurrentTasks <= 0 {
+		c.MaxConcurrentTasks = 1
+	}
+	if c.QueueOrder == "" {
+		c.QueueOrder = QueueOrderFIFO
+	}
+	c.Context = c.Context.withDefaults()
+	return c
+}
@@ -0,0 +1,224 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/tools"
+)
+
+const (
+	ToolOutputTruncate  = "truncate"
+	ToolOutputSummarize = "summarize"
+
+	// toolingTokens covers the tool definitions and the instructions the model
+	// client adds to the prompts built by the runtime.
+	toolingTokens = 800
+	// minContextTokens is the space always left for the history, even when the
+	// fixed parts of a prompt fill the context window.
+	minContextTokens = 256
+)
+
+// ContextConfig budgets the prompts so they fit in the context window of the model.
+type ContextConfig struct {
+	// MaxTokens is the context window of the model.
+	MaxTokens int `yaml:"max_tokens,omitempty"`
+	// ResponseTokens are kept free for the answer of the model.
+	ResponseTokens int `yaml:"response_tokens,omitempty"`
+	// ToolOutputTokens is the largest tool output passed as is, larger ones are truncated or summarized.
+	ToolOutputTokens int    `yaml:"tool_output_tokens,omitempty"`
+	ToolOutput       string `yaml:"tool_output,omitempty"`
+	// SummaryTokens is the size from which the summary of a task is compacted.
+	SummaryTokens int `yaml:"summary_tokens,omitempty"`
+}
+
+func (c ContextConfig) Validate() error {
+	if c.MaxTokens < 0 || c.ResponseTokens < 0 || c.ToolOutputTokens < 0 || c.SummaryTokens < 0 {
+		return fmt.Errorf("context limits cannot be negative")
+	}
+	if c.MaxTokens > 0 && c.ResponseTokens >= c.MaxTokens {
+		return fmt.Errorf("context response_tokens must be lower than max_tokens")
+	}
+	switch c.ToolOutput {
+	case "", ToolOutputTruncate, ToolOutputSummarize:
+	default:
+		return fmt.Errorf("unknown context tool_output %q, expected %s or %s",
+			c.ToolOutput, ToolOutputTruncate, ToolOutputSummarize)
+	}
+	return nil
+}
+
+func (c ContextConfig) withDefaults() ContextConfig {
+	if c.MaxTokens <= 0 {
+		c.MaxTokens = 8192
+	}
+	if c.ResponseTokens <= 0 {
+		c.ResponseTokens = 1024
+	}
+	if c.ToolOutputTokens <= 0 {
+		c.ToolOutputTokens = 1500
+	}
+	if c.ToolOutput == "" {
+		c.ToolOutput = ToolOutputTruncate
+	}
+	if c.SummaryTokens <= 0 {
+		c.SummaryTokens = 1500
+	}
+	return c
+}
+
+// availableTokens returns the tokens left in the context window once the fixed
+// parts of a prompt are counted with the tokenizer of the model.
+func (r *Runtime) availableTokens(model models.Interface, fixed ...string) int {
+	estimator := model.TokenEstimator()
+	tokens := r.config.Context.MaxTokens - r.config.Context.ResponseTokens - toolingTokens
+	for _, text := range fixed {
+		tokens -= estimator.Count(text)
+	}
+	return max(tokens, minContextTokens)
+}
+
+// fitRecords renders the latest records that fit in the tokens, oldest first.
+// The latest record is truncated when it doesn't fit on its own.
+func fitRecords(estimator models.TokenEstimator, records []storage.Record, tokens int) string {
+	var lines []string
+	for i := len(records) - 1; i >= 0; i-- {
+		line := storage.FormatRecord(records[i])
+		if line == "" {
+			continue
+		}
+		count := estimator.Count(line)
+		if count > tokens {
+			if len(lines) == 0 {
+				lines = append(lines, estimator.Truncate(line, tokens))
+			}
+			break
+		}
+		tokens -= count
+		lines = append(lines, line)
+	}
+	slices.Reverse(lines)
+	return strings.Join(lines, "")
+}
+
+// chunkRecords renders the records in chunks that fit in the tokens, a record
+// larger than the tokens is truncated to fill a chunk.
+func chunkRecords(estimator models.TokenEstimator, records []storage.Record, tokens int) []string {
+	var chunks []string
+	var chunk string
+	var used int
+	for _, record := range records {
+		line := storage.FormatRecord(record)
+		if line == "" {
+			continue
+		}
+		line = estimator.Truncate(line, tokens)
+		count := estimator.Count(line)
+		if used+count > tokens && chunk != "" {
+			chunks = append(chunks, chunk)
+			chunk, used = "", 0
+		}
+		chunk += line
+		used += count
+	}
+	if chunk != "" {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// summarizeRecords summarizes the records of the last steps, in several
+// requests when they don't fit in a single prompt.
+func (r *Runtime) summarizeRecords(ctx context.Context, leader *teams.Member, subtasks []string,
+	records []storage.Record) (string, error) {
+	systemPrompt := leader.Prompt(models.SummarySystemPrompt)
+	subtasksText := strings.Join(subtasks, "\n")
+	model := r.modelFor(leader)
+	tokens := r.availableTokens(model, systemPrompt, fmt.Sprintf(models.SummaryContextPrompt, subtasksText, ""))
+
+	chunks := chunkRecords(model.TokenEstimator(), records, tokens)
+	if len(chunks) == 0 {
+		chunks = []string{""}
+	}
+	summaries := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		userPrompt := fmt.Sprintf(models.SummaryContextPrompt, subtasksText, chunk)
+		summary, err := model.Think(ctx, models.CreateMessages(userPrompt, systemPrompt), 0.1, 1000)
+		if err != nil {
+			return "", err
+		}
+		summaries = append(summaries, summary)
+	}
+	return strings.Join(summaries, "\n"), nil
+}
+
+// compactSummary rewrites the summary of the task shorter once it grows over
+// the summary budget, the prompts that carry it must keep fitting in the window.
+func (r *Runtime) compactSummary(ctx context.Context, team *teams.Team, cp *storage.Checkpoint) {
+	model := r.modelFor(team.GetLeader())
+	estimator := model.TokenEstimator()
+	limit := r.config.Context.SummaryTokens
+	before := estimator.Count(cp.Summary)
+	if before <= limit {
+		return
+	}
+
+	systemPrompt := fmt.Sprintf(models.CompactSummarySystemPrompt, limit)
+	summary := estimator.Truncate(cp.Summary, r.availableTokens(model, systemPrompt))
+	compacted, err := model.Think(ctx, models.CreateMessages(summary, systemPrompt), 0.1, limit)
+	if err != nil || compacted == "" {
+		log.Printf("⚠️ Error compacting the summary of task %s: %v", cp.TaskID, err)
+		return
+	}
+	cp.Summary = compacted
+	team.Audits.Printf("🗜️ Summary compacted from %d to %d tokens", before, estimator.Count(compacted))
+}
+
+// limitToolOutput keeps the tool outputs within the tool output budget, the
+// larger ones are summarized by the model of the member or truncated.
+func (r *Runtime) limitToolOutput(ctx context.Context, team *teams.Team, member *teams.Member,
+	tool tools.Tool) tools.Tool {
+	handler := tool.HandlerFunc
+	if handler == nil {
+		return tool
+	}
+	model := r.modelFor(member)
+	tool.HandlerFunc = func(toolTask tools.ToolTask) (string, error) {
+		result, err := handler(toolTask)
+		estimator := model.TokenEstimator()
+		limit := r.config.Context.ToolOutputTokens
+		tokens := estimator.Count(result)
+		if tokens <= limit {
+			return result, err
+		}
+
+		if r.config.Context.ToolOutput == ToolOutputSummarize {
+			summary, sErr := r.summarizeToolOutput(ctx, model, tool.Name, toolTask, result)
+			if sErr == nil && summary != "" && estimator.Count(summary) <= limit {
+				team.Audits.Printf("🗜️ Output of %s summarized from %d to %d tokens",
+					tool.Name, tokens, estimator.Count(summary))
+				return summary, err
+			}
+		}
+		team.Audits.Printf("✂️ Output of %s truncated from %d to %d tokens", tool.Name, tokens, limit)
+		return estimator.Truncate(result, limit), err
+	}
+	return tool
+}
+
+func (r *Runtime) summarizeToolOutput(ctx context.Context, model models.Interface, toolName string,
+	toolTask tools.ToolTask, output string) (string, error) {
+	arguments, _ := json.Marshal(toolTask.Parameters)
+	systemPrompt := fmt.Sprintf(models.ToolOutputSummarySystemPrompt, r.config.Context.ToolOutputTokens)
+	header := fmt.Sprintf(models.ToolOutputSummaryPrompt, toolName, arguments, "")
+	output = model.TokenEstimator().Truncate(output, r.availableTokens(model, systemPrompt, header))
+	userPrompt := fmt.Sprintf(models.ToolOutputSummaryPrompt, toolName, arguments, output)
+	return model.Think(ctx, models.CreateMessages(userPrompt, systemPrompt), 0.1, r.config.Context.ToolOutputTokens)
+}
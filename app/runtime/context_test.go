@@ -0,0 +1,59 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/storage"
+)
+
+func contextRecords(contents ...string) []storage.Record {
+	records := make([]storage.Record, 0, len(contents))
+	for _, content := range contents {
+		records = append(records, storage.Record{Role: models.AssistantRole, Content: content})
+	}
+	return records
+}
+
+func TestFitRecords(t *testing.T) {
+	estimator := models.NewTokenEstimator("gpt")
+	records := contextRecords("first", "second", "third")
+	records = append(records, storage.Record{Role: models.UserRole, Content: "ignored"})
+
+	all := fitRecords(estimator, records, 1000)
+	assert.Equal(t, storage.RecordListToString(records, len(records)), all)
+
+	line := estimator.Count(storage.FormatRecord(records[2]))
+	latest := fitRecords(estimator, records, line+1)
+	assert.Contains(t, latest, "third")
+	assert.NotContains(t, latest, "second")
+
+	huge := contextRecords(strings.Repeat("x", 4000))
+	truncated := fitRecords(estimator, huge, 100)
+	assert.Contains(t, truncated, "tokens truncated")
+	assert.LessOrEqual(t, estimator.Count(truncated), 120)
+}
+
+func TestChunkRecords(t *testing.T) {
+	estimator := models.NewTokenEstimator("gpt")
+	records := contextRecords(strings.Repeat("a", 200), strings.Repeat("b", 200), strings.Repeat("c", 4000))
+
+	chunks := chunkRecords(estimator, records, 150)
+	assert.Len(t, chunks, 2)
+	assert.Contains(t, chunks[0], "aaa")
+	assert.Contains(t, chunks[0], "bbb")
+	assert.Contains(t, chunks[1], "tokens truncated")
+
+	assert.Empty(t, chunkRecords(estimator, nil, 150))
+}
+
+func TestContextConfigValidate(t *testing.T) {
+	assert.NoError(t, ContextConfig{}.Validate())
+	assert.NoError(t, ContextConfig{MaxTokens: 4096, ResponseTokens: 512, ToolOutput: ToolOutputSummarize}.Validate())
+	assert.Error(t, ContextConfig{MaxTokens: 512, ResponseTokens: 512}.Validate())
+	assert.Error(t, ContextConfig{ToolOutputTokens: -1}.Validate())
+	assert.Error(t, ContextConfig{ToolOutput: "drop"}.Validate())
+}
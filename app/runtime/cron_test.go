@@ -0,0 +1,51 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, time.March, 15, 10, 20, 30, 0, time.UTC) // Friday
+	cases := []struct {
+		name string
+		expr string
+		next time.Time
+	}{
+		{"every_minute", "* * * * *", time.Date(2024, time.March, 15, 10, 21, 0, 0, time.UTC)},
+		{"nightly", "30 2 * * *", time.Date(2024, time.March, 16, 2, 30, 0, 0, time.UTC)},
+		{"step", "*/15 * * * *", time.Date(2024, time.March, 15, 10, 30, 0, 0, time.UTC)},
+		{"weekdays", "0 9 * * 1-5", time.Date(2024, time.March, 18, 9, 0, 0, 0, time.UTC)},
+		{"sunday_as_7", "0 0 * * 7", time.Date(2024, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"list", "0 8,20 * * *", time.Date(2024, time.March, 15, 20, 0, 0, 0, time.UTC)},
+		{"monthly_macro", "@monthly", time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"dom_or_dow", "0 0 1 * 6", time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"leap_day", "0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, cse := range cases {
+		t.Run(cse.name, func(t *testing.T) {
+			c, err := parseCron(cse.expr)
+			assert.NoError(t, err)
+			assert.Equal(t, cse.next, c.next(from))
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, err := parseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestScheduleRender(t *testing.T) {
+	s := Schedule{Name: "nightly", Task: "Review the logs of {{.Yesterday}} ({{.Weekday}} {{.Date}} {{.Time}})"}
+	text, err := s.render(time.Date(2024, time.March, 1, 2, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "Review the logs of 2024-02-29 (Friday 2024-03-01 02:30)", text)
+
+	_, err = Schedule{Name: "bad", Task: "{{.Unknown}}"}.render(time.Now())
+	assert.Error(t, err)
+}
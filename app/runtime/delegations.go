@@ -0,0 +1,75 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/teams"
+)
+
+type delegation struct {
+	step   int
+	action models.DelegateAction
+	worker *teams.Member
+	output string
+	err    error
+}
+
+// finishAction returns the finish action when the leader closed the task,
+// a batch mixing "finish" with real work is treated as work in progress.
+func finishAction(actions []models.DelegateAction) *models.DelegateAction {
+	if len(actions) == 1 && actions[0].IsFinish() {
+		return &actions[0]
+	}
+	return nil
+}
+
+// runDelegations executes a batch of delegations, each one with its own step ID.
+// Delegations assigned to different members run concurrently, while the ones
+// assigned to the same member run in order to avoid stepping on its own work.
+func (r *Runtime) runDelegations(ctx context.Context, team *teams.Team, task *teams.Task,
+	actions []models.DelegateAction, firstStep int) []*delegation {
+	delegations := make([]*delegation, 0, len(actions))
+	byWorker := make(map[string][]*delegation)
+	var order []string
+
+	for i, action := range actions {
+		d := &delegation{
+			step:   firstStep + i,
+			action: action,
+			worker: team.GetMember(action.Worker),
+		}
+		delegations = append(delegations, d)
+		if d.worker == nil || action.IsFinish() {
+			d.err = fmt.Errorf("worker %s not found", action.Worker)
+			continue
+		}
+		if _, ok := byWorker[action.Worker]; !ok {
+			order = append(order, action.Worker)
+		}
+		byWorker[action.Worker] = append(byWorker[action.Worker], d)
+	}
+
+	var wg sync.WaitGroup
+	for _, key := range order {
+		wg.Add(1)
+		go func(queue []*delegation) {
+			defer wg.Done()
+			for _, d := range queue {
+				team.Audits.Printf("✅ Task assigned (step %d): %v", d.step, d.action)
+				r.publish(task, StepDelegated, StepDelegatedPayload{
+					Step:     d.step,
+					PlanStep: d.action.Step,
+					Worker:   d.worker.Key,
+					Task:     d.action.Task,
+				})
+				r.processDelegation(ctx, team, task, d)
+			}
+		}(byWorker[key])
+	}
+	wg.Wait()
+
+	return delegations
+}
@@ -0,0 +1,53 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/tools"
+)
+
+// DryRun replaces the tools of the workers with stubs, the tasks plan and
+// delegate as usual but nothing is executed on the workspace.
+type DryRun struct {
+	Enabled bool `yaml:"enabled"`
+	// Result is returned by every stubbed tool, empty asks the model to simulate a plausible result.
+	Result string `yaml:"result,omitempty"`
+	// Results overrides Result for the tools it names.
+	Results map[string]string `yaml:"results,omitempty"`
+}
+
+func (d DryRun) result(toolName string) string {
+	if result, ok := d.Results[toolName]; ok {
+		return result
+	}
+	return d.Result
+}
+
+// simulateTool returns a stub of the tool that logs the intended call and
+// answers with the canned result or one generated by the model of the member.
+func (r *Runtime) simulateTool(ctx context.Context, team *teams.Team, member *teams.Member,
+	tool tools.Tool) tools.Tool {
+	if tool.HandlerFunc == nil {
+		return tool
+	}
+	model := r.modelFor(member)
+	tool.HandlerFunc = func(toolTask tools.ToolTask) (string, error) {
+		arguments, _ := json.Marshal(toolTask.Parameters)
+		team.Audits.Printf("🧪 [dry-run] %s would call %s with %s", toolTask.MemberKey, tool.Name, arguments)
+
+		if result := r.config.DryRun.result(tool.Name); result != "" {
+			return result, nil
+		}
+		userPrompt := fmt.Sprintf(models.DryRunToolPrompt, tool.Name, tool.Description, arguments)
+		result, err := model.Think(ctx, models.CreateMessages(userPrompt, models.DryRunSystemPrompt), 0.3, 800)
+		if err != nil || result == "" {
+			return fmt.Sprintf("%s executed successfully.", tool.Name), nil
+		}
+		return result, nil
+	}
+	return tool
+}
@@ -6,8 +6,11 @@ import (
 )
 
 const (
-	NewTask    = "new_task"
-	CancelTask = "cancel_task"
+	NewTask       = "new_task"
+	CancelTask    = "cancel_task"
+	ResumeTask    = "resume_task"
+	PauseTask     = "pause_task"
+	InjectMessage = "inject_message"
 )
 
 var EventsHandlerFuncDefault = map[string]func(r *Runtime, ev Event) string{
@@ -15,39 +18,81 @@ var EventsHandlerFuncDefault = map[string]func(r *Runtime, ev Event) string{
 		if ev.Task == nil {
 			return "No new task detected to start."
 		}
-
-		r.mu.Lock()
-		prevCancel := r.cancelFunc
-
-		if prevCancel != nil {
-			log.Println("🛑 Canceling current task before starting a new one.")
-			prevCancel()
+		if ev.Task.Origin == "" {
+			ev.Task.Origin = ev.Origin
 		}
 
-		ctx, cancel := context.WithCancel(context.Background())
-
-		r.team.Task = ev.Task
-		r.activeTask.Store(true)
-		r.mu.Unlock()
-
-		go func() {
-			if err := r.runTask(ctx, cancel); err != nil {
-				log.Printf("Error running task: %v", err)
-			}
-		}()
+		if err := r.SubmitTask(context.Background(), ev.Task); err != nil {
+			log.Printf("❌ Error queuing new task: %v", err)
+			return NewTask
+		}
 
 		return NewTask
 	},
 
 	CancelTask: func(r *Runtime, ev Event) string {
-		if !r.activeTask.CompareAndSwap(true, false) {
+		if ev.TaskID != "" {
+			if err := r.CancelTask(context.Background(), ev.TaskID); err != nil {
+				log.Printf("⚠️ Couldn't cancel task %s: %v", ev.TaskID, err)
+				return CancelTask
+			}
+			log.Printf("🛑 Canceling task %s.", ev.TaskID)
+			return CancelTask
+		}
+
+		if r.runningCount() == 0 {
 			log.Println("⚠️ No active task to cancel.")
 			return CancelTask
 		}
 
 		r.StopRuntime()
 
-		log.Println("🛑 Canceling active task.")
+		log.Println("🛑 Canceling active tasks.")
 		return CancelTask
 	},
+
+	ResumeTask: func(r *Runtime, ev Event) string {
+		if ev.TaskID == "" {
+			log.Println("⚠️ No task ID to resume.")
+			return ResumeTask
+		}
+
+		if err := r.ResumeTask(context.Background(), ev.TaskID); err != nil {
+			log.Printf("⚠️ Couldn't resume task %s: %v", ev.TaskID, err)
+			return ResumeTask
+		}
+
+		log.Printf("⏯️ Resuming task %s.", ev.TaskID)
+		return ResumeTask
+	},
+
+	PauseTask: func(r *Runtime, ev Event) string {
+		if ev.TaskID == "" {
+			log.Println("⚠️ No task ID to pause.")
+			return PauseTask
+		}
+
+		if err := r.PauseTask(context.Background(), ev.TaskID); err != nil {
+			log.Printf("⚠️ Couldn't pause task %s: %v", ev.TaskID, err)
+			return PauseTask
+		}
+
+		log.Printf("⏸️ Task %s will pause after its current step.", ev.TaskID)
+		return PauseTask
+	},
+
+	InjectMessage: func(r *Runtime, ev Event) string {
+		if ev.TaskID == "" || ev.Message == "" {
+			log.Println("⚠️ Missing task ID or message to inject.")
+			return InjectMessage
+		}
+
+		if err := r.InjectMessage(context.Background(), ev.TaskID, ev.Message); err != nil {
+			log.Printf("⚠️ Couldn't send message to task %s: %v", ev.TaskID, err)
+			return InjectMessage
+		}
+
+		log.Printf("💬 Message added to task %s.", ev.TaskID)
+		return InjectMessage
+	},
 }
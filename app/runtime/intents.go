@@ -0,0 +1,168 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/tools"
+	"GoWorkerAI/app/utils"
+)
+
+// searchResults is how many records search_history returns.
+const searchResults = 10
+
+// ProcessQuickEvent answers a chat message with the event handler of the
+// default team. Admins also get its tools, which turn the requests into events
+// for the tasks of every team, like the commands of the clients.
+func (rt *Router) ProcessQuickEvent(ctx context.Context, key SessionKey, message string, admin bool) string {
+	r := rt.Default()
+	if r == nil {
+		return "No default team."
+	}
+	var toolkit map[string]tools.Tool
+	if handler := r.team.GetEventHandler(); admin && handler != nil {
+		toolkit = rt.eventToolkit(ctx, key, handler.GetToolKit())
+	}
+	return r.ProcessQuickEvent(ctx, key, message, toolkit)
+}
+
+// eventToolkit binds the tools of the event handler to the router. Only the
+// intent tools are kept, the rest of its toolkit would run outside any task.
+func (rt *Router) eventToolkit(ctx context.Context, key SessionKey, toolkit map[string]tools.Tool) map[string]tools.Tool {
+	handlers := map[string]func(tools.ToolTask) (string, error){
+		tools.CreateTaskTool:     rt.createTaskTool(key),
+		tools.CancelTaskTool:     rt.cancelTaskTool(key),
+		tools.GetTaskStatusTool:  rt.taskStatusTool(ctx),
+		tools.SearchHistoryTool:  rt.searchHistoryTool(ctx),
+		tools.InjectIntoTaskTool: rt.injectTool(key),
+	}
+	bound := make(map[string]tools.Tool, len(handlers))
+	for name, handler := range handlers {
+		tool, ok := toolkit[name]
+		if !ok {
+			continue
+		}
+		tool.HandlerFunc = handler
+		bound[name] = recoverTool(tool)
+	}
+	return bound
+}
+
+func (rt *Router) createTaskTool(key SessionKey) func(tools.ToolTask) (string, error) {
+	return func(toolTask tools.ToolTask) (string, error) {
+		action, err := utils.CastAny[tools.CreateTaskAction](toolTask.Parameters)
+		if err != nil {
+			return "", err
+		}
+		if action.Description == "" {
+			return "", fmt.Errorf("description cannot be empty")
+		}
+		task := &teams.Task{
+			ID:          uuid.New(),
+			Description: action.Description,
+			Origin:      key.Origin,
+			Channel:     key.Channel,
+			Priority:    action.Priority,
+		}
+		if err = rt.QueueEvent(Event{
+			Origin:      key.Origin,
+			Team:        action.Team,
+			Task:        task,
+			HandlerFunc: EventsHandlerFuncDefault[NewTask],
+		}); err != nil {
+			return "", err
+		}
+		team := action.Team
+		if team == "" {
+			team = rt.Default().TeamName()
+		}
+		return fmt.Sprintf("Task %s queued on team %s.", task.ID, team), nil
+	}
+}
+
+func (rt *Router) cancelTaskTool(key SessionKey) func(tools.ToolTask) (string, error) {
+	return func(toolTask tools.ToolTask) (string, error) {
+		action, err := utils.CastAny[tools.TaskAction](toolTask.Parameters)
+		if err != nil {
+			return "", err
+		}
+		if rt.findTask(action.TaskID) == nil {
+			return "", fmt.Errorf("task %q not found", action.TaskID)
+		}
+		if err = rt.QueueEvent(Event{
+			Origin:      key.Origin,
+			TaskID:      action.TaskID,
+			HandlerFunc: EventsHandlerFuncDefault[CancelTask],
+		}); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Task %s will be cancelled.", action.TaskID), nil
+	}
+}
+
+func (rt *Router) taskStatusTool(ctx context.Context) func(tools.ToolTask) (string, error) {
+	return func(toolTask tools.ToolTask) (string, error) {
+		action, err := utils.CastAny[tools.TaskAction](toolTask.Parameters)
+		if err != nil {
+			return "", err
+		}
+		if action.TaskID == "" {
+			return rt.GetQueueStatusText(ctx), nil
+		}
+		return rt.GetTaskResultText(ctx, action.TaskID), nil
+	}
+}
+
+func (rt *Router) searchHistoryTool(ctx context.Context) func(tools.ToolTask) (string, error) {
+	return func(toolTask tools.ToolTask) (string, error) {
+		action, err := utils.CastAny[tools.SearchHistoryAction](toolTask.Parameters)
+		if err != nil {
+			return "", err
+		}
+		if action.Query == "" {
+			return "", fmt.Errorf("query cannot be empty")
+		}
+		// Every team shares the same storage.
+		records, err := rt.Default().db.SearchHistory(ctx, action.Query, action.TaskID, searchResults)
+		if err != nil {
+			return "", err
+		}
+		if len(records) == 0 {
+			return fmt.Sprintf("No history matches %q.", action.Query), nil
+		}
+		lines := make([]string, 0, len(records))
+		for _, record := range records {
+			lines = append(lines, fmt.Sprintf("- [task %s, step %d, %s] %s", record.TaskID, record.SubTaskID,
+				record.MemberID, utils.Truncate(record.Content, 300)))
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+}
+
+func (rt *Router) injectTool(key SessionKey) func(tools.ToolTask) (string, error) {
+	return func(toolTask tools.ToolTask) (string, error) {
+		action, err := utils.CastAny[tools.InjectAction](toolTask.Parameters)
+		if err != nil {
+			return "", err
+		}
+		if action.Message == "" {
+			return "", fmt.Errorf("message cannot be empty")
+		}
+		if rt.findTask(action.TaskID) == nil {
+			return "", fmt.Errorf("task %q not found", action.TaskID)
+		}
+		if err = rt.QueueEvent(Event{
+			Origin:      key.Origin,
+			TaskID:      action.TaskID,
+			Message:     action.Message,
+			HandlerFunc: EventsHandlerFuncDefault[InjectMessage],
+		}); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Instruction sent to task %s, the leader will see it on its next delegation.", action.TaskID), nil
+	}
+}
@@ -0,0 +1,28 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoWorkerAI/app/tools"
+)
+
+func TestEventToolkitKeepsOnlyIntentTools(t *testing.T) {
+	called := false
+	toolkit := map[string]tools.Tool{
+		tools.CreateTaskTool: {Name: tools.CreateTaskTool},
+		"write_file": {Name: "write_file", HandlerFunc: func(tools.ToolTask) (string, error) {
+			called = true
+			return "", nil
+		}},
+	}
+
+	bound := (&Router{}).eventToolkit(context.Background(), SessionKey{}, toolkit)
+	assert.Len(t, bound, 1)
+	assert.Contains(t, bound, tools.CreateTaskTool)
+	assert.NotContains(t, bound, "write_file")
+	assert.NotNil(t, bound[tools.CreateTaskTool].HandlerFunc)
+	assert.False(t, called)
+}
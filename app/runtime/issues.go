@@ -0,0 +1,123 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/tools"
+	"GoWorkerAI/app/utils"
+)
+
+// escalateIssue wraps report_issue so every reported issue is stored and sent
+// to the clients, where a human can answer it.
+func (r *Runtime) escalateIssue(ctx context.Context, team *teams.Team, tool tools.Tool) tools.Tool {
+	handler := tool.HandlerFunc
+	if handler == nil {
+		return tool
+	}
+	tool.HandlerFunc = func(toolTask tools.ToolTask) (string, error) {
+		result, err := handler(toolTask)
+		if err != nil {
+			return result, err
+		}
+		action, err := utils.CastAny[tools.IssueAction](toolTask.Parameters)
+		if err != nil {
+			return result, nil
+		}
+
+		issue := storage.Issue{
+			TaskID:   team.Task.ID.String(),
+			Team:     team.Name,
+			StepID:   toolTask.StepID,
+			MemberID: toolTask.MemberKey,
+			Severity: action.Level(),
+			Reason:   action.Reason,
+			Status:   storage.IssueOpen,
+		}
+		if issue.ID, err = r.db.SaveIssue(ctx, issue); err != nil {
+			log.Printf("⚠️ Error saving issue of task %s: %v", issue.TaskID, err)
+			return result, nil
+		}
+		team.Audits.Printf("🚩 Issue %d (%s) reported by %s on step %d: %s",
+			issue.ID, issue.Severity, issue.MemberID, issue.StepID, issue.Reason)
+		r.publish(team.Task, IssueReported, IssueReportedPayload{Issue: issue})
+		return fmt.Sprintf("%s (issue %d)", result, issue.ID), nil
+	}
+	return tool
+}
+
+// issuesContext lists the issues of the task for the leader, the open ones
+// first since they block the work that depends on them.
+func (r *Runtime) issuesContext(ctx context.Context, task *teams.Task) string {
+	issues, err := r.db.GetTaskIssues(ctx, task.ID.String())
+	if err != nil {
+		log.Printf("⚠️ Error reading issues of task %s: %v", task.ID.String(), err)
+		return ""
+	}
+	var open, answered []string
+	for _, issue := range issues {
+		line := fmt.Sprintf("- [%s] %s on step %d: %s", issue.Severity, issue.MemberID, issue.StepID, issue.Reason)
+		if issue.Status == storage.IssueOpen {
+			open = append(open, "BLOCKING "+line+" (waiting for a human answer)")
+			continue
+		}
+		answered = append(answered, fmt.Sprintf("%s\n  Answer from %s: %s", line, issue.AnsweredBy, issue.Answer))
+	}
+	return strings.Join(append(open, answered...), "\n")
+}
+
+// AnswerIssue stores the answer of a human to an open issue of the team, the
+// leader reads it on its next delegation.
+func (r *Runtime) AnswerIssue(ctx context.Context, id int64, answer, answeredBy string) error {
+	if answer == "" {
+		return fmt.Errorf("answer cannot be empty")
+	}
+	issue, err := r.db.GetIssue(ctx, id)
+	if err != nil {
+		return err
+	}
+	if issue == nil || issue.Team != r.team.Name {
+		return fmt.Errorf("issue %d not found", id)
+	}
+	if err = r.db.AnswerIssue(ctx, id, answer, answeredBy); err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	run, running := r.running[issue.TaskID]
+	r.mu.RUnlock()
+	if running {
+		run.team.Audits.Printf("💬 Issue %d answered by %s: %s", id, answeredBy, answer)
+	}
+	return nil
+}
+
+func (r *Runtime) GetOpenIssuesText(ctx context.Context) string {
+	issues, err := r.db.GetOpenIssues(ctx, r.team.Name)
+	if err != nil {
+		return "Couldn't read the issues: " + err.Error()
+	}
+	if len(issues) == 0 {
+		return "No open issues."
+	}
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		lines = append(lines, fmt.Sprintf("- %d [%s] %s on step %d of task %s: %s", issue.ID, issue.Severity,
+			issue.MemberID, issue.StepID, issue.TaskID, utils.Truncate(issue.Reason, 300)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseIssueID reads the id of an issue as typed by a user.
+func ParseIssueID(value string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(value, "#"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid issue id %q", value)
+	}
+	return id, nil
+}
@@ -0,0 +1,218 @@
+package runtime
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/utils"
+)
+
+type LifecycleType string
+
+const (
+	TaskStarted      LifecycleType = "task_started"
+	PlanCreated      LifecycleType = "plan_created"
+	StepDelegated    LifecycleType = "step_delegated"
+	ToolCalled       LifecycleType = "tool_called"
+	ToolResult       LifecycleType = "tool_result"
+	SummaryUpdated   LifecycleType = "summary_updated"
+	TaskFinished     LifecycleType = "task_finished"
+	TaskFailed       LifecycleType = "task_failed"
+	TaskPaused       LifecycleType = "task_paused"
+	TaskResumed      LifecycleType = "task_resumed"
+	MessageInjected  LifecycleType = "message_injected"
+	ApprovalRequired LifecycleType = "approval_required"
+	IssueReported    LifecycleType = "issue_reported"
+)
+
+// subscriberBuffer is the number of events a slow subscriber can fall behind
+// before new events are dropped for it.
+const subscriberBuffer = 256
+
+// LifecycleEvent is published by the runtime while it works on a task, the
+// Payload type depends on the event Type.
+type LifecycleEvent struct {
+	Type    LifecycleType
+	Team    string
+	TaskID  string
+	Origin  string
+	Channel string
+	Time    time.Time
+	Payload any
+}
+
+type TaskStartedPayload struct {
+	Description string
+	Resumed     bool
+}
+
+type PlanCreatedPayload struct {
+	Plan teams.Plan
+}
+
+type StepDelegatedPayload struct {
+	Step     int
+	PlanStep int
+	Worker   string
+	Task     string
+}
+
+type ToolCalledPayload struct {
+	Step      int
+	Member    string
+	Tool      string
+	Arguments map[string]any
+}
+
+type ToolResultPayload struct {
+	Step   int
+	Member string
+	Tool   string
+	Result string
+	Error  string
+}
+
+type SummaryUpdatedPayload struct {
+	Step     int
+	Summary  string
+	Progress string
+}
+
+type TaskFinishedPayload struct {
+	Reason   string
+	Steps    int
+	Tokens   int64
+	Duration time.Duration
+}
+
+type TaskFailedPayload struct {
+	Status string
+	Error  string
+}
+
+type TaskPausedPayload struct {
+	Step int
+}
+
+type TaskResumedPayload struct {
+	Step int
+}
+
+type MessageInjectedPayload struct {
+	Message string
+}
+
+type ApprovalRequiredPayload struct {
+	Approval Approval
+}
+
+type IssueReportedPayload struct {
+	Issue storage.Issue
+}
+
+// Message renders the event as a human readable update for the clients.
+func (e LifecycleEvent) Message() string {
+	switch p := e.Payload.(type) {
+	case TaskStartedPayload:
+		if p.Resumed {
+			return fmt.Sprintf("⏯️ Task %s resumed: %s", e.TaskID, utils.Truncate(p.Description, 200))
+		}
+		return fmt.Sprintf("▶️ Task %s started: %s", e.TaskID, utils.Truncate(p.Description, 200))
+	case PlanCreatedPayload:
+		title := "📋 Plan"
+		if p.Plan.Revision > 0 {
+			title = fmt.Sprintf("🔁 Plan revision %d", p.Plan.Revision)
+		}
+		return fmt.Sprintf("%s for task %s:\n%s", title, e.TaskID, p.Plan.String())
+	case StepDelegatedPayload:
+		return fmt.Sprintf("➡️ Task %s step %d assigned to %s: %s", e.TaskID, p.Step, p.Worker, utils.Truncate(p.Task, 200))
+	case ToolCalledPayload:
+		return fmt.Sprintf("🔧 %s called %s on step %d of task %s", p.Member, p.Tool, p.Step, e.TaskID)
+	case ToolResultPayload:
+		if p.Error != "" {
+			return fmt.Sprintf("⚠️ %s failed on step %d of task %s: %s", p.Tool, p.Step, e.TaskID, p.Error)
+		}
+		return fmt.Sprintf("✅ %s finished on step %d of task %s", p.Tool, p.Step, e.TaskID)
+	case SummaryUpdatedPayload:
+		return fmt.Sprintf("📍 Task %s, %s:\n%s", e.TaskID, p.Progress, utils.Truncate(p.Summary, 1500))
+	case TaskFinishedPayload:
+		return fmt.Sprintf("✅ Task %s completed in %d steps (%d tokens, %s): %s",
+			e.TaskID, p.Steps, p.Tokens, p.Duration.Round(time.Second), p.Reason)
+	case TaskFailedPayload:
+		if p.Error == "" {
+			return fmt.Sprintf("❌ Task %s stopped as %s", e.TaskID, p.Status)
+		}
+		return fmt.Sprintf("❌ Task %s stopped as %s: %s", e.TaskID, p.Status, p.Error)
+	case TaskPausedPayload:
+		return fmt.Sprintf("⏸️ Task %s paused after step %d, use resume to continue", e.TaskID, p.Step)
+	case TaskResumedPayload:
+		return fmt.Sprintf("▶️ Task %s resumed at step %d", e.TaskID, p.Step+1)
+	case MessageInjectedPayload:
+		return fmt.Sprintf("💬 Instruction for task %s: %s", e.TaskID, utils.Truncate(p.Message, 500))
+	case ApprovalRequiredPayload:
+		a := p.Approval
+		return fmt.Sprintf("🔐 Approval %s required: %s wants to run %s with %s (task %s, step %d).\n"+
+			"Reply with !approve %s or !reject %s <reason>.",
+			a.ID, a.Member, a.Tool, utils.Truncate(a.Arguments, 500), a.TaskID, a.StepID, a.ID, a.ID)
+	case IssueReportedPayload:
+		i := p.Issue
+		return fmt.Sprintf("🚩 Issue %d (%s) reported by %s on step %d of task %s: %s\n"+
+			"Reply with !answer %d <answer>.",
+			i.ID, i.Severity, i.MemberID, i.StepID, e.TaskID, utils.Truncate(i.Reason, 1000), i.ID)
+	}
+	return fmt.Sprintf("%s: task %s", e.Type, e.TaskID)
+}
+
+// Subscribe registers a listener for the lifecycle events of every task. Each
+// subscriber receives the events in order on its own goroutine, so a slow
+// client never blocks the tasks.
+func (r *Runtime) Subscribe(listener func(LifecycleEvent)) {
+	events := make(chan LifecycleEvent, subscriberBuffer)
+	r.mu.Lock()
+	r.subscribers = append(r.subscribers, events)
+	r.mu.Unlock()
+
+	go func() {
+		for ev := range events {
+			deliver(listener, ev)
+		}
+	}()
+}
+
+// deliver calls the listener, a panicking client loses the event but keeps its subscription.
+func deliver(listener func(LifecycleEvent), ev LifecycleEvent) {
+	defer utils.RecoverPanic("subscriber of "+string(ev.Type), nil)
+	listener(ev)
+}
+
+func (r *Runtime) publish(task *teams.Task, eventType LifecycleType, payload any) {
+	ev := LifecycleEvent{
+		Type:    eventType,
+		Team:    r.team.Name,
+		TaskID:  task.ID.String(),
+		Origin:  task.Origin,
+		Channel: task.Channel,
+		Time:    time.Now(),
+		Payload: payload,
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.subscribers) == 0 {
+		switch ev.Type {
+		case ApprovalRequired, IssueReported, TaskFinished, TaskFailed:
+			log.Printf("📣 %s", ev.Message())
+		}
+		return
+	}
+	for _, events := range r.subscribers {
+		select {
+		case events <- ev:
+		default:
+			log.Printf("⚠️ Subscriber too slow, dropping %s event of task %s", ev.Type, ev.TaskID)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/tools"
+)
+
+// maxStepFailures is the number of failed delegations of a single step that triggers a re-plan.
+const maxStepFailures = 2
+
+func (r *Runtime) createPlan(ctx context.Context, team *teams.Team) (teams.Plan, error) {
+	task := team.Task
+	messages := models.CreateMessages(task.Description, team.GetLeader().Prompt(models.PlanSystemPrompt))
+	planText, err := r.modelFor(team.GetLeader()).Think(ctx, messages, 0.25, -1)
+	if err != nil {
+		return teams.Plan{}, err
+	}
+
+	plan := teams.ParsePlan(planText)
+	if len(plan.Steps) == 0 {
+		plan = teams.ParsePlan("1. " + task.Description)
+	}
+	return plan, nil
+}
+
+// replan asks the leader for the remaining steps and appends them to the plan,
+// skipping the steps that were not finished.
+func (r *Runtime) replan(ctx context.Context, team *teams.Team, plan *teams.Plan, summary, problem string) error {
+	userPrompt := fmt.Sprintf(models.ReplanContextPrompt, team.Task.Description, plan.String(), summary, problem)
+	messages := models.CreateMessages(userPrompt, team.GetLeader().Prompt(models.ReplanSystemPrompt))
+	planText, err := r.modelFor(team.GetLeader()).Think(ctx, messages, 0.25, -1)
+	if err != nil {
+		return err
+	}
+
+	next := teams.ParsePlan(planText)
+	if len(next.Steps) == 0 {
+		return fmt.Errorf("re-plan returned no steps")
+	}
+	plan.Revise(next)
+	return nil
+}
+
+// trackDelegations updates the plan with the outcome of a batch and returns the
+// reason to re-plan, empty when the plan still holds.
+func (r *Runtime) trackDelegations(ctx context.Context, task *teams.Task, plan *teams.Plan,
+	delegations []*delegation) string {
+	var problems []string
+	for _, d := range delegations {
+		step := plan.Resolve(d.action.Step)
+		if step == nil {
+			continue
+		}
+
+		issues := r.reportedIssues(ctx, task, d.step)
+		switch {
+		case len(issues) > 0:
+			plan.SetStatus(step.Number, teams.StepFailed)
+			problems = append(problems, fmt.Sprintf("%s reported on step %d: %s",
+				d.action.Worker, step.Number, strings.Join(issues, "; ")))
+		case d.err != nil:
+			plan.SetStatus(step.Number, teams.StepFailed)
+			if step.Failures >= maxStepFailures {
+				problems = append(problems, fmt.Sprintf("step %d failed %d times, last error: %v",
+					step.Number, step.Failures, d.err))
+			}
+		default:
+			plan.SetStatus(step.Number, teams.StepDone)
+		}
+	}
+	return strings.Join(problems, "\n")
+}
+
+func (r *Runtime) startSteps(plan *teams.Plan, actions []models.DelegateAction) {
+	for i, action := range actions {
+		if step := plan.Resolve(action.Step); step != nil {
+			actions[i].Step = step.Number
+			plan.SetStatus(step.Number, teams.StepInProgress)
+		}
+	}
+}
+
+func (r *Runtime) reportedIssues(ctx context.Context, task *teams.Task, stepID int) []string {
+	records, err := r.db.GetHistoryByTaskID(ctx, task.ID.String(), stepID)
+	if err != nil {
+		return nil
+	}
+	var issues []string
+	for _, record := range records {
+		if record.Tool == tools.ReportIssueTool {
+			issues = append(issues, record.Content)
+		}
+	}
+	return issues
+}
+
+func (r *Runtime) setPlan(taskID string, plan teams.Plan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if run, ok := r.running[taskID]; ok {
+		run.plan = plan
+	}
+}
+
+// GetTaskProgress returns the plan progress of a running task as "step N of M".
+func (r *Runtime) GetTaskProgress(taskID string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	run, ok := r.running[taskID]
+	if !ok {
+		return ""
+	}
+	return run.plan.ProgressText()
+}
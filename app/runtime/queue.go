@@ -0,0 +1,239 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/utils"
+)
+
+// SubmitTask stores the task in the persistent queue, it starts as soon as a
+// concurrency slot is free.
+func (r *Runtime) SubmitTask(ctx context.Context, task *teams.Task) error {
+	if task == nil || task.Description == "" {
+		return fmt.Errorf("task description cannot be empty")
+	}
+	if task.ID == uuid.Nil {
+		task.ID = uuid.New()
+	}
+
+	var budget string
+	if task.Budget != nil {
+		raw, err := json.Marshal(task.Budget)
+		if err != nil {
+			return fmt.Errorf("encode task budget: %w", err)
+		}
+		budget = string(raw)
+	}
+
+	if err := r.db.EnqueueTask(ctx, storage.QueuedTask{
+		TaskID:      task.ID.String(),
+		Team:        r.team.Name,
+		Description: task.Description,
+		Origin:      task.Origin,
+		Channel:     task.Channel,
+		Priority:    task.Priority,
+		Budget:      budget,
+		Status:      storage.TaskPending,
+		CreatedAt:   time.Now(),
+	}); err != nil {
+		return fmt.Errorf("enqueue task: %w", err)
+	}
+
+	r.wake()
+	return nil
+}
+
+// CancelTask cancels a running task or drops a pending one from the queue.
+func (r *Runtime) CancelTask(ctx context.Context, taskID string) error {
+	r.mu.RLock()
+	run, ok := r.running[taskID]
+	r.mu.RUnlock()
+	if ok {
+		run.cancel()
+		return nil
+	}
+
+	queued, err := r.db.GetQueuedTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if queued == nil || queued.Team != r.team.Name {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	if queued.Status != storage.TaskPending {
+		return fmt.Errorf("task %s is not pending (status: %s)", taskID, queued.Status)
+	}
+	return r.db.UpdateTaskStatus(ctx, taskID, storage.TaskCancelled)
+}
+
+func (r *Runtime) GetQueueStatus(ctx context.Context) ([]storage.QueuedTask, error) {
+	return r.db.GetQueuedTasks(ctx, r.team.Name, storage.TaskPending, storage.TaskRunning, storage.TaskPaused)
+}
+
+func (r *Runtime) GetQueueStatusText(ctx context.Context) string {
+	queued, err := r.GetQueueStatus(ctx)
+	if err != nil {
+		return "Couldn't read the task queue: " + err.Error()
+	}
+	if len(queued) == 0 {
+		return "The task queue is empty."
+	}
+
+	lines := make([]string, 0, len(queued)+1)
+	lines = append(lines, fmt.Sprintf("📊 %d task(s) in queue, up to %d running at once:",
+		len(queued), r.config.MaxConcurrentTasks))
+	for _, t := range queued {
+		lines = append(lines, fmt.Sprintf("- [%s] %s (priority %d): %s",
+			t.Status, t.TaskID, t.Priority, utils.Truncate(t.Description, 80)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *Runtime) wake() {
+	select {
+	case r.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Runtime) runningCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.running)
+}
+
+// dispatch starts queued tasks until the concurrency limit is reached.
+func (r *Runtime) dispatch(ctx context.Context) {
+	for r.runningCount() < r.config.MaxConcurrentTasks {
+		next, err := r.db.ClaimNextTask(ctx, r.team.Name, r.config.QueueOrder == QueueOrderPriority)
+		if err != nil {
+			log.Printf("⚠️ Error claiming next task: %v", err)
+			return
+		}
+		if next == nil {
+			return
+		}
+		r.startTask(*next)
+	}
+}
+
+// saveTaskError stores the error of a task that did not complete, with the
+// stack trace when it panicked.
+func (r *Runtime) saveTaskError(taskID string, runErr error) {
+	if runErr == nil {
+		return
+	}
+	if err := r.db.SaveTaskError(context.Background(), taskID, errorMessage(runErr)); err != nil {
+		log.Printf("⚠️ Error saving error of task %s: %v", taskID, err)
+	}
+}
+
+// errorMessage returns the message of the error, followed by the stack trace when it is a panic.
+func errorMessage(err error) string {
+	message := err.Error()
+	var panicErr *utils.PanicError
+	if errors.As(err, &panicErr) {
+		message += "\n" + panicErr.Stack
+	}
+	return message
+}
+
+// taskStatus maps the result of runTask to the status saved in the queue.
+func taskStatus(ctx context.Context, err error) string {
+	switch {
+	case ctx.Err() != nil:
+		return storage.TaskCancelled
+	case errors.Is(err, ErrBudgetExhausted):
+		return storage.TaskBudgetOut
+	case err != nil:
+		return storage.TaskFailed
+	}
+	return storage.TaskCompleted
+}
+
+// resultStatus maps the queue status of a finished task to the status of its result.
+func resultStatus(status string) string {
+	switch status {
+	case storage.TaskCompleted:
+		return storage.ResultSucceeded
+	case storage.TaskCancelled:
+		return storage.ResultCancelled
+	case storage.TaskBudgetOut:
+		return storage.ResultBudgetExhausted
+	}
+	return storage.ResultFailed
+}
+
+func (r *Runtime) startTask(queued storage.QueuedTask) {
+	taskID, err := uuid.Parse(queued.TaskID)
+	if err != nil {
+		log.Printf("⚠️ Invalid task ID %s in queue: %v", queued.TaskID, err)
+		_ = r.db.UpdateTaskStatus(context.Background(), queued.TaskID, storage.TaskFailed)
+		return
+	}
+
+	task := &teams.Task{
+		ID:          taskID,
+		Description: queued.Description,
+		Origin:      queued.Origin,
+		Channel:     queued.Channel,
+		Priority:    queued.Priority,
+	}
+	if queued.Budget != "" {
+		task.Budget = &teams.Budget{}
+		if err = json.Unmarshal([]byte(queued.Budget), task.Budget); err != nil {
+			log.Printf("⚠️ Invalid budget for task %s, using team budget: %v", queued.TaskID, err)
+			task.Budget = nil
+		}
+	}
+	audits, err := utils.NewWorkerLogger("team_logs_"+queued.TaskID, utils.GetColors()[0], 10000)
+	if err != nil {
+		log.Printf("❌ Failed to create logger for task %s: %v", queued.TaskID, err)
+		_ = r.db.UpdateTaskStatus(context.Background(), queued.TaskID, storage.TaskFailed)
+		return
+	}
+	team := r.team.Clone(task)
+	team.Audits = audits
+
+	taskCtx, cancel := context.WithCancel(context.Background())
+	r.mu.Lock()
+	r.running[queued.TaskID] = &taskRun{task: task, team: team, cancel: cancel}
+	r.mu.Unlock()
+
+	go func() {
+		defer r.wake()
+		defer cancel()
+
+		_, runErr := r.runTask(taskCtx, team)
+		status := taskStatus(taskCtx, runErr)
+		if status == storage.TaskFailed {
+			log.Printf("Error running task: %v", runErr)
+		}
+
+		if err := r.db.UpdateTaskStatus(context.Background(), queued.TaskID, status); err != nil {
+			log.Printf("⚠️ Error saving status of task %s: %v", queued.TaskID, err)
+		}
+		r.saveTaskError(queued.TaskID, runErr)
+		if status != storage.TaskCompleted {
+			failure := TaskFailedPayload{Status: status}
+			if runErr != nil {
+				failure.Error = runErr.Error()
+			}
+			r.publish(task, TaskFailed, failure)
+		}
+
+		r.mu.Lock()
+		delete(r.running, queued.TaskID)
+		r.mu.Unlock()
+	}()
+}
@@ -0,0 +1,55 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/utils"
+)
+
+// GetTaskResult returns the result of a finished task, nil while it has not finished.
+func (r *Runtime) GetTaskResult(ctx context.Context, taskID string) (*storage.TaskResult, error) {
+	return r.db.GetTaskResult(ctx, taskID)
+}
+
+func (r *Runtime) GetTaskResultText(ctx context.Context, taskID string) string {
+	result, err := r.GetTaskResult(ctx, taskID)
+	if err != nil {
+		return "Couldn't read the result of the task: " + err.Error()
+	}
+	if result == nil {
+		queued, err := r.db.GetQueuedTask(ctx, taskID)
+		if err != nil || queued == nil {
+			return fmt.Sprintf("Task %s not found.", taskID)
+		}
+		return fmt.Sprintf("Task %s is %s, it has no result yet.", taskID, queued.Status)
+	}
+	return formatTaskResult(*result)
+}
+
+func formatTaskResult(result storage.TaskResult) string {
+	lines := []string{
+		fmt.Sprintf("📋 Task %s (team %s): %s", result.TaskID, result.Team, result.Status),
+		"📝 " + utils.Truncate(result.Description, 200),
+		fmt.Sprintf("📊 %d step(s), %d token(s), %s", result.Steps, result.Tokens, result.Duration.Round(1e9)),
+	}
+	if result.Reason != "" {
+		lines = append(lines, "✅ Reason: "+utils.Truncate(result.Reason, 500))
+	}
+	if result.Answer != "" {
+		lines = append(lines, "💬 Answer: "+utils.Truncate(result.Answer, 1000))
+	}
+	if result.Error != "" {
+		message, _, _ := strings.Cut(result.Error, "\n")
+		lines = append(lines, "❌ Error: "+utils.Truncate(message, 500))
+	}
+	if len(result.Files) > 0 {
+		lines = append(lines, fmt.Sprintf("📁 %d file(s) changed:", len(result.Files)))
+		for _, file := range result.Files {
+			lines = append(lines, fmt.Sprintf("- [%s] %s", file.Change, file.Path))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
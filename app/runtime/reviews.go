@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/utils"
+)
+
+// processDelegation runs a delegation on its worker and, when the team review
+// policy applies, sends the output to the reviewer. A rejected output goes back
+// to the same worker with the reason until it is approved or the rounds run out.
+func (r *Runtime) processDelegation(ctx context.Context, team *teams.Team, task *teams.Task, d *delegation) {
+	defer utils.RecoverPanic(fmt.Sprintf("step %d of task %s", d.step, task.ID.String()), &d.err)
+	if d.worker.SubTeam != nil {
+		d.output, d.err = r.runSubTeam(ctx, team, d)
+		return
+	}
+	messages := models.CreateMessages(d.action.Task, d.worker.Prompt(d.action.Context))
+	reviewer := team.GetReviewer()
+	rounds := team.Review.Rounds()
+	for round := 0; ; round++ {
+		d.output, d.err = r.modelFor(d.worker).Process(ctx, d.worker.Key, team.Audits.Logger, messages,
+			d.worker.GetToolKit(), task.ID.String(), d.step, d.worker.MaxToolRounds)
+		if d.err != nil || reviewer == nil || !team.Review.Applies(d.worker.Key, d.action.Step) {
+			return
+		}
+
+		approved, reason, err := r.review(ctx, team, task, reviewer, d)
+		if err != nil {
+			log.Printf("⚠️ Skipping review of step %d: %v", d.step, err)
+			return
+		}
+		if approved {
+			team.Audits.Printf("✅ Reviewer approved step %d: %s", d.step, reason)
+			return
+		}
+		if round >= rounds {
+			team.Audits.Printf("⚠️ Reviewer rejected step %d after %d revision(s), moving on: %s", d.step, round, reason)
+			return
+		}
+		team.Audits.Printf("🔁 Reviewer rejected step %d (revision %d of %d): %s", d.step, round+1, rounds, reason)
+		systemPrompt := d.worker.Prompt(d.action.Context)
+		model := r.modelFor(d.worker)
+		tokens := r.availableTokens(model, systemPrompt, fmt.Sprintf(models.RevisionPrompt, "", reason, d.action.Task))
+		previous := model.TokenEstimator().Truncate(d.output, tokens)
+		messages = models.CreateMessages(fmt.Sprintf(models.RevisionPrompt, previous, reason, d.action.Task),
+			systemPrompt)
+	}
+}
+
+func (r *Runtime) review(ctx context.Context, team *teams.Team, task *teams.Task, reviewer *teams.Member,
+	d *delegation) (bool, string, error) {
+	records, err := r.db.GetHistoryByTaskID(ctx, task.ID.String(), d.step)
+	if err != nil {
+		return false, "", err
+	}
+	systemPrompt := reviewer.Prompt(models.ReviewSystemPrompt)
+	model := r.modelFor(reviewer)
+	tokens := r.availableTokens(model, systemPrompt, fmt.Sprintf(models.ReviewContextPrompt, d.action.Task, d.output, ""))
+	userPrompt := fmt.Sprintf(models.ReviewContextPrompt, d.action.Task, d.output,
+		fitRecords(model.TokenEstimator(), records, tokens))
+	messages := models.CreateMessages(userPrompt, systemPrompt)
+	approved, reason, err := model.TrueOrFalse(ctx, messages)
+	if err != nil {
+		return false, "", err
+	}
+
+	verdict := "REJECTED"
+	if approved {
+		verdict = "APPROVED"
+	}
+	if err = r.db.SaveHistory(ctx, storage.Record{
+		TaskID:    task.ID.String(),
+		SubTaskID: int64(d.step),
+		MemberID:  reviewer.Key,
+		Role:      models.AssistantRole,
+		Content:   fmt.Sprintf("Review of %s: %s %s", d.worker.Key, verdict, reason),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		log.Printf("⚠️ Error saving review of step %d: %v", d.step, err)
+	}
+	return approved, reason, nil
+}
@@ -0,0 +1,204 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Router holds the runtime of every team and sends the events of the clients
+// to the team they target, the default team when they don't name one.
+type Router struct {
+	runtimes    map[string]*Runtime
+	defaultTeam string
+}
+
+func NewRouter(defaultTeam string, runtimes ...*Runtime) *Router {
+	router := &Router{
+		runtimes:    make(map[string]*Runtime, len(runtimes)),
+		defaultTeam: defaultTeam,
+	}
+	for _, rt := range runtimes {
+		router.runtimes[rt.TeamName()] = rt
+	}
+	return router
+}
+
+// Start runs every team runtime until the context is done.
+func (rt *Router) Start(ctx context.Context) {
+	for _, r := range rt.runtimes {
+		go r.Start(ctx)
+	}
+}
+
+func (rt *Router) StopRuntime() {
+	for _, r := range rt.runtimes {
+		r.StopRuntime()
+	}
+}
+
+func (rt *Router) Teams() []string {
+	names := make([]string, 0, len(rt.runtimes))
+	for name := range rt.runtimes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default returns the runtime of the default team.
+func (rt *Router) Default() *Runtime {
+	return rt.runtimes[rt.defaultTeam]
+}
+
+// Get returns the runtime of the team, the default one for an empty name.
+func (rt *Router) Get(team string) (*Runtime, error) {
+	if team == "" {
+		team = rt.defaultTeam
+	}
+	r, ok := rt.runtimes[team]
+	if !ok {
+		return nil, fmt.Errorf("team %s not found, available teams: %s", team, strings.Join(rt.Teams(), ", "))
+	}
+	return r, nil
+}
+
+// Subscribe registers the listener on every team, events carry the team name.
+func (rt *Router) Subscribe(listener func(LifecycleEvent)) {
+	for _, r := range rt.runtimes {
+		r.Subscribe(listener)
+	}
+}
+
+// QueueEvent sends the event to the team it names, to the team owning its
+// task, or to the default team.
+func (rt *Router) QueueEvent(ev Event) error {
+	r, err := rt.route(ev)
+	if err != nil {
+		return err
+	}
+	r.QueueEvent(ev)
+	return nil
+}
+
+// Broadcast sends the event to every team.
+func (rt *Router) Broadcast(ev Event) {
+	for _, r := range rt.runtimes {
+		r.QueueEvent(ev)
+	}
+}
+
+func (rt *Router) route(ev Event) (*Runtime, error) {
+	if ev.Team != "" {
+		return rt.Get(ev.Team)
+	}
+	if ev.TaskID != "" {
+		if r := rt.findTask(ev.TaskID); r != nil {
+			return r, nil
+		}
+	}
+	return rt.Get("")
+}
+
+func (rt *Router) findTask(taskID string) *Runtime {
+	for _, r := range rt.runtimes {
+		r.mu.RLock()
+		_, running := r.running[taskID]
+		r.mu.RUnlock()
+		if running {
+			return r
+		}
+	}
+	// Every team shares the same storage, the queue knows the team of the task.
+	r := rt.Default()
+	if r == nil {
+		return nil
+	}
+	queued, err := r.db.GetQueuedTask(context.Background(), taskID)
+	if err != nil || queued == nil {
+		return nil
+	}
+	return rt.runtimes[queued.Team]
+}
+
+func (rt *Router) ResolveApproval(id string, decision ApprovalDecision) error {
+	for _, r := range rt.runtimes {
+		r.mu.RLock()
+		_, ok := r.approvals[id]
+		r.mu.RUnlock()
+		if ok {
+			return r.ResolveApproval(id, decision)
+		}
+	}
+	return fmt.Errorf("approval %s not found or already resolved", id)
+}
+
+func (rt *Router) PendingApprovals() []Approval {
+	var approvals []Approval
+	for _, name := range rt.Teams() {
+		approvals = append(approvals, rt.runtimes[name].PendingApprovals()...)
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		return approvals[i].RequestedAt.Before(approvals[j].RequestedAt)
+	})
+	return approvals
+}
+
+// AnswerIssue sends the answer to the team that owns the issue.
+func (rt *Router) AnswerIssue(ctx context.Context, id int64, answer, answeredBy string) error {
+	r := rt.Default()
+	if r == nil {
+		return fmt.Errorf("no default team")
+	}
+	issue, err := r.db.GetIssue(ctx, id)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		return fmt.Errorf("issue %d not found", id)
+	}
+	if r, err = rt.Get(issue.Team); err != nil {
+		return err
+	}
+	return r.AnswerIssue(ctx, id, answer, answeredBy)
+}
+
+func (rt *Router) GetOpenIssuesText(ctx context.Context) string {
+	return rt.eachTeam(func(r *Runtime) string { return r.GetOpenIssuesText(ctx) })
+}
+
+// GetTaskResultText describes the result of a task of any team, they share the same storage.
+func (rt *Router) GetTaskResultText(ctx context.Context, taskID string) string {
+	r := rt.Default()
+	if r == nil {
+		return "No default team."
+	}
+	return r.GetTaskResultText(ctx, taskID)
+}
+
+func (rt *Router) GetTaskStatus() string {
+	return rt.eachTeam(func(r *Runtime) string { return r.GetTaskStatus() })
+}
+
+func (rt *Router) GetQueueStatusText(ctx context.Context) string {
+	return rt.eachTeam(func(r *Runtime) string { return r.GetQueueStatusText(ctx) })
+}
+
+func (rt *Router) GetSchedulesText(ctx context.Context) string {
+	return rt.eachTeam(func(r *Runtime) string { return r.GetSchedulesText(ctx) })
+}
+
+// eachTeam joins the text of every team, with a header per team when there is more than one.
+func (rt *Router) eachTeam(text func(r *Runtime) string) string {
+	if len(rt.runtimes) == 1 {
+		for _, r := range rt.runtimes {
+			return text(r)
+		}
+	}
+	parts := make([]string, 0, len(rt.runtimes))
+	for _, name := range rt.Teams() {
+		parts = append(parts, fmt.Sprintf("👥 Team %s:\n%s", name, text(rt.runtimes[name])))
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -2,71 +2,116 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"os"
-	"runtime/debug"
 	"strings"
 	"sync"
-	"sync/atomic"
+	"time"
 
 	"GoWorkerAI/app/models"
 	"GoWorkerAI/app/rag"
 	"GoWorkerAI/app/storage"
 	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/utils"
 )
 
 type Runtime struct {
-	mu         sync.RWMutex
-	team       *teams.Team
-	model      models.Interface
-	rag        rag.Interface
-	db         storage.Interface
-	events     chan Event
-	activeTask atomic.Bool
-	cancelFunc context.CancelFunc
-	context    context.Context
+	mu      sync.RWMutex
+	team    *teams.Team
+	model   models.Interface
+	rag     rag.Interface
+	db      storage.Interface
+	config  Config
+	events  chan Event
+	wakeup  chan struct{}
+	running map[string]*taskRun
+
+	subscribers []chan LifecycleEvent
+	approvals   map[string]*Approval
+}
+
+type taskRun struct {
+	task   *teams.Task
+	team   *teams.Team
+	plan   teams.Plan
+	cancel context.CancelFunc
+	// resume is open while the task is paused and closed to let it continue.
+	resume chan struct{}
 }
 
-func NewRuntime(t *teams.Team, m models.Interface, db storage.Interface, rag rag.Interface) *Runtime {
+func NewRuntime(t *teams.Team, m models.Interface, db storage.Interface, rag rag.Interface, cfg Config) *Runtime {
 	rt := &Runtime{
-		team:   t,
-		model:  m,
-		rag:    rag,
-		events: make(chan Event, 1024),
-		db:     db,
+		team:    t,
+		model:   m,
+		rag:     rag,
+		config:  cfg.withDefaults(),
+		events:  make(chan Event, 1024),
+		wakeup:  make(chan struct{}, 1),
+		running: make(map[string]*taskRun),
+		db:      db,
+
+		approvals: make(map[string]*Approval),
 	}
+	rt.assignModels(t)
 	return rt
 }
 
-func handlePanic() {
-	if rec := recover(); rec != nil {
-		log.Printf("❌ Panic recovered in runTask: %v\nStack trace:\n%s", rec, debug.Stack())
+// assignModels gives the members with their own model settings a client for
+// them, sub-teams included. Clones of the team share the clients.
+func (r *Runtime) assignModels(team *teams.Team) {
+	for _, member := range team.Members {
+		if member.SubTeam != nil {
+			r.assignModels(member.SubTeam)
+		}
+		if !member.Settings.IsZero() {
+			member.Model = r.model.WithSettings(member.Settings)
+		}
+	}
+}
+
+// modelFor returns the client for the calls of the member, the runtime model
+// when the member has no settings of its own.
+func (r *Runtime) modelFor(member *teams.Member) models.Interface {
+	if member != nil && member.Model != nil {
+		return member.Model
 	}
-	os.Exit(666)
+	return r.model
 }
 
+func (r *Runtime) TeamName() string {
+	return r.team.Name
+}
+
+// Start runs the runtime until the context is done. The event loop and the
+// scheduler are restarted when they panic, the tasks recover on their own.
 func (r *Runtime) Start(ctx context.Context) {
-	taskRunning := r.activeTask.Load()
-	defer handlePanic()
-
-	if !taskRunning && r.team.Task != nil && r.team.Task.Description != "" {
-		r.activeTask.Store(true)
-		taskCtx, taskCancel := context.WithCancel(context.Background())
-		r.mu.Lock()
-		r.context = taskCtx
-		r.cancelFunc = taskCancel
-		r.mu.Unlock()
-
-		go func() {
-			defer r.activeTask.Store(false)
-			if err := r.runTask(taskCtx, taskCancel); err != nil {
-				log.Printf("Error running task: %v", err)
-			}
-		}()
+	interruptedStatus := storage.TaskInterrupted
+	if r.config.ResumeOnStart {
+		interruptedStatus = storage.TaskPending
+	}
+	if n, err := r.db.MarkRunningTasks(ctx, r.team.Name, interruptedStatus); err != nil {
+		log.Printf("⚠️ Error marking interrupted tasks: %v", err)
+	} else if n > 0 {
+		log.Printf("🔁 %d interrupted task(s) marked as %s", n, interruptedStatus)
+	}
 
+	r.mu.Lock()
+	initialTask := r.team.Task
+	r.team.Task = nil
+	r.mu.Unlock()
+	if initialTask != nil && initialTask.Description != "" {
+		if err := r.SubmitTask(ctx, initialTask); err != nil {
+			log.Printf("⚠️ Error queuing initial task: %v", err)
+		}
 	}
 
+	go r.supervise(ctx, "scheduler", r.runScheduler)
+	r.supervise(ctx, "event loop", r.loop)
+}
+
+func (r *Runtime) loop(ctx context.Context) {
+	r.dispatch(ctx)
 	for {
 		select {
 		case <-ctx.Done():
@@ -76,90 +121,255 @@ func (r *Runtime) Start(ctx context.Context) {
 				log.Println("runtime: Event channel closed.")
 			}
 			r.handleEvent(ev)
+		case <-r.wakeup:
+			r.dispatch(ctx)
 		}
 	}
 }
 
+// StopRuntime cancels every running task.
 func (r *Runtime) StopRuntime() {
 	r.mu.Lock()
-	r.team.Task = nil
-	for _, member := range r.team.Members {
-		member.Task = nil
-	}
-	if r.cancelFunc != nil {
-		r.cancelFunc()
-		r.cancelFunc = nil
+	for _, run := range r.running {
+		run.cancel()
 	}
 	r.mu.Unlock()
 }
 
+// QueueEvent never blocks the caller, when the event buffer is full the
+// event is handled on its own goroutine instead.
 func (r *Runtime) QueueEvent(event Event) {
-	r.events <- event
+	select {
+	case r.events <- event:
+	default:
+		log.Printf("⚠️ Event buffer full, handling event from %s asynchronously", event.Origin)
+		go r.handleEvent(event)
+	}
 }
 
-func (r *Runtime) runTask(ctx context.Context, cancel context.CancelFunc) error {
-	r.mu.Lock()
-	r.context = ctx
-	r.cancelFunc = cancel
-	team := r.team
+// runTask executes the task of the team until it stops and saves its result,
+// the answer and reason come from the leader or the workflow.
+func (r *Runtime) runTask(ctx context.Context, team *teams.Team) (result storage.TaskResult, err error) {
 	task := team.Task
-	leader := team.GetLeader()
-	teamOptions := strings.Join(r.team.GetMembersOptions(), "\n")
-	r.mu.Unlock()
-
 	if task == nil {
 		log.Println("⚠️ Worker returned nil task.")
-		return nil
+		return result, nil
 	}
 
+	result = storage.TaskResult{
+		TaskID:      task.ID.String(),
+		Team:        team.Name,
+		Description: task.Description,
+		StartedAt:   time.Now(),
+	}
+	workspace := snapshotWorkspace(r.config.Workspace)
+	run := workspaceRuns.begin(r.config.Workspace, task.Root().ID.String())
+	taskCtx := ctx
+	ctx, usage := models.WithUsage(ctx)
+	defer func() {
+		result.Status = resultStatus(taskStatus(taskCtx, err))
+		result.Tokens = usage.TotalTokens()
+		result.Duration = time.Since(result.StartedAt)
+		// The changes of the tasks running at the same time can't be told apart, so none are listed.
+		if shared := workspaceRuns.end(run); shared && workspace != nil {
+			log.Printf("⚠️ Task %s shared the workspace with other tasks, its changed files are not listed",
+				result.TaskID)
+		} else {
+			result.Files = workspaceChanges(workspace, snapshotWorkspace(r.config.Workspace))
+		}
+		result.FinishedAt = time.Now()
+		if err != nil {
+			result.Error = errorMessage(err)
+		}
+		if sErr := r.db.SaveTaskResult(context.Background(), result); sErr != nil {
+			log.Printf("⚠️ Error saving result of task %s: %v", result.TaskID, sErr)
+		}
+	}()
+	defer utils.RecoverPanic("task "+task.ID.String(), &err)
+	defer func() {
+		if err := team.Close(); err != nil {
+			log.Printf("⚠️ Error closing team: %v", err)
+		}
+	}()
+
+	budget := newBudgetTracker(team, task, usage)
+	ctx, cancel := budget.withDeadline(ctx)
+	defer cancel()
+	r.instrumentTools(ctx, team)
+
 	team.Audits.Printf("▶️ Starting task: %s", task.Description)
-	log.Printf("=" + strings.Repeat("=", 80))
+	if r.config.DryRun.Enabled {
+		team.Audits.Printf("🧪 Dry run: tool calls are simulated, the workspace is not modified")
+	}
+	log.Print(strings.Repeat("=", 81))
 	log.Printf("📋 TASK ID: %s", task.ID.String())
 	log.Printf("📝 DESCRIPTION: %s", task.Description)
 	log.Printf("👥 TEAM MEMBERS: %d", len(team.Members))
-	log.Printf("=" + strings.Repeat("=", 80))
-
-	messages := models.CreateMessages(task.Description, leader.Prompt(models.PlanSystemPrompt))
+	log.Print(strings.Repeat("=", 81))
 
-	planText, err := r.model.Think(ctx, messages, 0.25, -1)
+	cp, history, err := r.loadCheckpoint(ctx, task)
 	if err != nil {
-		log.Printf("❌ Error generating plan: %v\n", err)
-		return err
+		log.Printf("⚠️ Error loading checkpoint for task %s, planning from scratch: %v", task.ID.String(), err)
 	}
+	r.publish(task, TaskStarted, TaskStartedPayload{Description: task.Description, Resumed: cp != nil})
 
-	//planText := task.Description
-	team.Audits.Printf("✅ Plan generated:\n%s\n", planText)
+	useLeader := team.Workflow == nil || cp != nil
+	if !useLeader {
+		if err = r.runWorkflow(ctx, team, budget, &result); err != nil {
+			if !team.Workflow.FallbackToLeader || !errors.Is(err, errWorkflowStep) {
+				return result, err
+			}
+			team.Audits.Printf("↩️ Falling back to leader mode: %v", err)
+			useLeader = true
+			history, _ = r.db.GetHistoryByTaskID(ctx, task.ID.String(), -1)
+			if len(history) > resumeHistory {
+				history = history[len(history)-resumeHistory:]
+			}
+		}
+	}
+	if useLeader {
+		if err = r.runLeader(ctx, team, budget, cp, history, &result); err != nil {
+			return result, err
+		}
+	}
 
-	var i int
-	var summarizedRecords int
-	var history []storage.Record
+	team.Audits.Printf("=" + strings.Repeat("=", 80))
+	team.Audits.Printf("✅ TASK COMPLETED")
+	team.Audits.Printf("📋 TASK ID: %s", task.ID.String())
+	team.Audits.Printf("📊 TOTAL STEPS: %d", result.Steps)
+	team.Audits.Printf("🔢 TOTAL TOKENS: %d", usage.TotalTokens())
+	team.Audits.Printf("=" + strings.Repeat("=", 80))
+
+	log.Printf("📄 Task logs saved to: logs/team_logs_%s.log", task.ID.String())
+	r.publish(task, TaskFinished, TaskFinishedPayload{
+		Reason:   result.Reason,
+		Steps:    result.Steps,
+		Tokens:   usage.TotalTokens(),
+		Duration: time.Since(result.StartedAt),
+	})
+	return result, nil
+}
+
+// runLeader lets the leader plan the task and delegate it step by step until it
+// is finished, continuing from the checkpoint when there is one. It counts the
+// steps on the result, which already holds the steps of a failed workflow, and
+// sets the last answer of the workers and the reason the leader gave to finish.
+func (r *Runtime) runLeader(ctx context.Context, team *teams.Team, budget *budgetTracker, cp *storage.Checkpoint,
+	history []storage.Record, result *storage.TaskResult) error {
+	task := team.Task
+	leader := team.GetLeader()
+	leaderModel := r.modelFor(leader)
+	teamOptions := strings.Join(team.GetMembersOptions(), "\n")
+
+	var plan teams.Plan
+	if cp != nil {
+		plan = teams.DecodePlan(cp.Plan)
+		team.Audits.Printf("⏯️ Resuming task from step %d with plan:\n%s\n", cp.Step, plan.String())
+	} else {
+		var err error
+		plan, err = r.createPlan(ctx, team)
+		if err != nil {
+			log.Printf("❌ Error generating plan: %v\n", err)
+			return err
+		}
+
+		team.Audits.Printf("✅ Plan generated:\n%s\n", plan.String())
+		cp = &storage.Checkpoint{TaskID: task.ID.String(), Step: result.Steps}
+		r.saveCheckpoint(ctx, cp, plan)
+		r.publish(task, PlanCreated, PlanCreatedPayload{Plan: plan})
+	}
+	r.setPlan(task.ID.String(), plan)
+	defer func() { result.Steps = cp.Step }()
+	completion := r.completionPolicy(team.Completion)
+	// unfinished is why the last completion check failed, the leader sees it on the next delegation.
+	var unfinished string
+
+	var err error
 	for {
-		i++
-		var delegateAction *models.DelegateAction
-		var summary string
-		prompt := leader.Prompt("Task to complete:\n" + task.Description + "\nLast actions logs:\n" + storage.RecordListToString(history, 10))
-		delegateAction, err = r.model.Delegate(ctx, teamOptions, planText, prompt)
-		if err != nil || delegateAction == nil {
-			log.Printf("❌ Skipping step %d. Error delegating: %v", i, err)
-			continue
+		r.waitIfPaused(ctx, team, cp.Step)
+		if err = budget.check(cp.Step); err != nil {
+			r.exhaustBudget(team, err)
+			return err
 		}
-		if delegateAction.Worker == "none" && delegateAction.Task == "finish" {
-			team.Audits.Printf("✅ Plan finished: %s", delegateAction.Context)
-			break
+		if err = ctx.Err(); err != nil {
+			team.Audits.Printf("🛑 Task %s stopped: %v", task.ID.String(), err)
+			return err
 		}
 
-		worker := r.team.GetMember(delegateAction.Worker)
-		if worker == nil {
-			log.Printf("❌ Skipping step %v. Worker %s not found.", delegateAction, delegateAction.Worker)
+		var extra string
+		if instructions := r.userInstructions(ctx, task); len(instructions) > 0 {
+			extra += fmt.Sprintf(models.UserInstructionsPrompt, "- "+strings.Join(instructions, "\n- "))
+		}
+		if issues := r.issuesContext(ctx, task); issues != "" {
+			extra += fmt.Sprintf(models.IssuesPrompt, issues)
+		}
+		if unfinished != "" {
+			extra += fmt.Sprintf(models.UnfinishedPrompt, unfinished)
+		}
+		header := "Task to complete:\n" + task.Description + "\nLast actions logs:\n"
+		historyTokens := r.availableTokens(leaderModel, leader.Prompt(header), extra, teamOptions, plan.String())
+		prompt := leader.Prompt(header+fitRecords(leaderModel.TokenEstimator(), history, historyTokens)) + extra
+		var actions []models.DelegateAction
+		actions, err = leaderModel.Delegate(ctx, teamOptions, plan.String(), prompt, team.DelegateWorkers())
+		if err != nil || len(actions) == 0 {
+			log.Printf("❌ Skipping step %d. Error delegating: %v", cp.Step+1, err)
+			budget.fail()
 			continue
 		}
+		if finish := finishAction(actions); finish != nil {
+			// A configured policy must agree, the leader could finish with the checks still failing.
+			if team.Completion != nil {
+				done, reason, cErr := completion.Complete(ctx, CompletionCheck{Team: team, Plan: plan, Summary: cp.Summary})
+				if cErr != nil || !done {
+					if cErr != nil {
+						reason = cErr.Error()
+					}
+					team.Audits.Printf("❌ Leader finished but the completion check failed: %s", reason)
+					unfinished = reason
+					budget.fail()
+					continue
+				}
+			}
+			team.Audits.Printf("✅ Plan finished: %s", finish.Context)
+			result.Reason = finish.Context
+			return nil
+		}
 
-		team.Audits.Printf("✅ Task assigned: %v", delegateAction)
-		prompt = worker.Prompt(delegateAction.Context)
-		messages = models.CreateMessages(delegateAction.Task, prompt)
-		_, err = r.model.Process(ctx, worker.Key, team.Audits.Logger, messages, worker.GetToolKit(), task.ID.String(), i)
-		if err != nil {
-			log.Printf("❌ Skipping step %d. Error processing: %v", i, err)
+		if remaining := budget.remainingSteps(cp.Step); remaining >= 0 && len(actions) > remaining {
+			actions = actions[:remaining]
+		}
+
+		r.startSteps(&plan, actions)
+		delegations := r.runDelegations(ctx, team, task, actions, cp.Step+1)
+		cp.Step += len(delegations)
+		problem := r.trackDelegations(ctx, task, &plan, delegations)
+		r.setPlan(task.ID.String(), plan)
+		r.saveCheckpoint(ctx, cp, plan)
+		team.Audits.Printf("📍 Progress: %s", plan.ProgressText())
+
+		if problem != "" {
+			team.Audits.Printf("🔁 Re-planning: %s", problem)
+			if err = r.replan(ctx, team, &plan, cp.Summary, problem); err != nil {
+				log.Printf("❌ Error re-planning task %s: %v", task.ID.String(), err)
+			} else {
+				team.Audits.Printf("✅ Plan revised:\n%s\n", plan.String())
+				r.setPlan(task.ID.String(), plan)
+				r.saveCheckpoint(ctx, cp, plan)
+				r.publish(task, PlanCreated, PlanCreatedPayload{Plan: plan})
+			}
+		}
+
+		var subtasks []string
+		for _, d := range delegations {
+			if d.err != nil {
+				log.Printf("❌ Skipping step %d. Error processing %v: %v", d.step, d.action, d.err)
+				continue
+			}
+			subtasks = append(subtasks, fmt.Sprintf("[%s] %s", d.worker.Key, d.action.Task))
+			result.Answer = d.output
+		}
+		if len(subtasks) == 0 {
+			budget.fail()
 			continue
 		}
 
@@ -167,45 +377,59 @@ func (r *Runtime) runTask(ctx context.Context, cancel context.CancelFunc) error
 		var newSummary string
 		var reason string
 		history, _ = r.db.GetHistoryByTaskID(ctx, task.ID.String(), -1)
-		history = history[summarizedRecords:]
-		newSummary = storage.RecordListToString(history, 100)
-		userPrompt := fmt.Sprintf(models.SummaryContextPrompt, delegateAction.Task, newSummary)
-		messages = models.CreateMessages(userPrompt, leader.Prompt(models.SummarySystemPrompt))
-		newSummary, err = r.model.Think(ctx, messages, 0.1, 1000)
+		history = history[min(cp.SummarizedRecords, len(history)):]
+		newSummary, err = r.summarizeRecords(ctx, leader, subtasks, history)
 		if err != nil {
-			log.Printf("❌ Skipping step %d. Error summarizing: %v", i, err)
+			log.Printf("❌ Skipping step %d. Error summarizing: %v", cp.Step, err)
+			budget.fail()
 			continue
 		}
 		team.Audits.Print(newSummary)
-		summary += "\n" + newSummary
-		summarizedRecords += len(history)
+		cp.Summary += "\n" + newSummary
+		cp.SummarizedRecords += len(history)
+		r.compactSummary(ctx, team, cp)
+		r.saveCheckpoint(ctx, cp, plan)
+		r.publish(task, SummaryUpdated, SummaryUpdatedPayload{
+			Step:     cp.Step,
+			Summary:  newSummary,
+			Progress: plan.ProgressText(),
+		})
 
-		messages = models.CreateMessages(fmt.Sprintf("Task : %s\n Summary: %s", planText, summary),
-			leader.Prompt(models.TaskDoneBoolPrompt))
-		finish, reason, err = r.model.TrueOrFalse(ctx, messages)
+		finish, reason, err = completion.Complete(ctx, CompletionCheck{Team: team, Plan: plan, Summary: cp.Summary})
+		if err != nil {
+			log.Printf("❌ Error checking completion of step %d: %v", cp.Step, err)
+			budget.fail()
+			continue
+		}
+		budget.succeed()
 		if finish {
 			team.Audits.Printf("✅ Plan finished: %s", reason)
-			break
-		} else {
-			team.Audits.Printf("❌Plan still not finished, reason: %s", reason)
+			result.Reason = reason
+			return nil
 		}
-
+		team.Audits.Printf("❌Plan still not finished, reason: %s", reason)
+		unfinished = reason
 	}
 
-	team.Audits.Printf("=" + strings.Repeat("=", 80))
-	team.Audits.Printf("✅ TASK COMPLETED")
-	team.Audits.Printf("📋 TASK ID: %s", task.ID.String())
-	team.Audits.Printf("📊 TOTAL STEPS: %d", i)
-	team.Audits.Printf("=" + strings.Repeat("=", 80))
-
-	if err := r.team.Close(); err != nil {
-		log.Printf("⚠️ Error closing team: %v", err)
-	}
-
-	log.Printf("📄 Task logs saved to: logs/team_logs_%s.log", task.ID.String())
-	return nil
 }
 
 func (r *Runtime) GetTaskStatus() string {
-	return strings.Join(r.team.Audits.GetLastLogs(100), "\n")
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.running) == 0 {
+		return "No active tasks."
+	}
+
+	var sb strings.Builder
+	for id, run := range r.running {
+		progress := run.plan.ProgressText()
+		if run.resume != nil {
+			progress += ", paused"
+		}
+		sb.WriteString(fmt.Sprintf("📋 Task %s (%s): %s\n", id, progress, run.task.Description))
+		sb.WriteString(strings.Join(run.team.Audits.GetLastLogs(100/len(r.running)), "\n"))
+		sb.WriteString("\n")
+	}
+	return sb.String()
 }
@@ -0,0 +1,137 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/tools"
+	"GoWorkerAI/app/utils"
+)
+
+// fakeLLM answers like a model that plans two steps, delegates them to the
+// coder and the writer at once and approves the result.
+func fakeLLM(calls *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var request struct {
+			Messages []models.Message `json:"messages"`
+			Tools    []struct {
+				Function struct {
+					Name string `json:"name"`
+				} `json:"function"`
+			} `json:"tools"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		names := make([]string, 0, len(request.Tools))
+		for _, tool := range request.Tools {
+			names = append(names, tool.Function.Name)
+		}
+
+		message := map[string]any{"role": "assistant"}
+		call := func(name string, arguments any) {
+			args, _ := json.Marshal(arguments)
+			message["tool_calls"] = []map[string]any{{
+				"id": "call_1", "type": "function",
+				"function": map[string]any{"name": name, "arguments": string(args)},
+			}}
+		}
+		system := request.Messages[0].Content
+		switch {
+		case strings.Contains(strings.Join(names, ","), "delegate_tasks"):
+			call("delegate_tasks", models.DelegateBatch{Tasks: []models.DelegateAction{
+				{Worker: "coder", Task: "Write the code", Step: 1},
+				{Worker: "writer", Task: "Write the docs", Step: 2},
+			}})
+		case strings.Contains(strings.Join(names, ","), "true_or_false"):
+			call("true_or_false", tools.ReviewerAction{Answer: "true", Reason: "code and docs are written"})
+		case strings.Contains(system, "You write code"):
+			message["content"] = "code written"
+		case strings.Contains(system, "You write docs"):
+			message["content"] = "docs written"
+		default:
+			message["content"] = "1. Write the code\n2. Write the docs"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": message}},
+			"usage":   map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+		})
+	}
+}
+
+func newTestTeam() *teams.Team {
+	workerTools := func(names ...string) map[string]tools.Tool {
+		toolkit := make(map[string]tools.Tool, len(names))
+		for _, name := range names {
+			toolkit[name] = tools.Tool{Name: name, Description: "Test tool " + name,
+				HandlerFunc: func(tools.ToolTask) (string, error) { return "ok", nil }}
+		}
+		return toolkit
+	}
+	return teams.NewTeam("test", []*teams.Member{
+		teams.NewMember("leader", "", "", &teams.Worker{System: "You lead.", Rules: []string{"Delegate."}}),
+		teams.NewMember("coder", "", "Code changes", &teams.Worker{System: "You write code.",
+			Rules: []string{"Test it."}, Toolkit: workerTools("read_file", "write_file", "run_tests")}),
+		teams.NewMember("writer", "", "Documentation", &teams.Worker{System: "You write docs.",
+			Rules: []string{"Be brief."}, Toolkit: workerTools("read_file", "write_file")}),
+	}, "Add a feature with docs")
+}
+
+func runCassetteTask(t *testing.T, mode, cassette string) storage.TaskResult {
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "database.db"))
+	db := storage.NewSQLiteStorage()
+	client, err := models.NewCassetteClient(db, "test", "", mode, cassette)
+	require.NoError(t, err)
+
+	team := newTestTeam()
+	r := NewRuntime(team, client, db, nil, Config{})
+	task := &teams.Task{ID: uuid.New(), Description: team.Task.Description}
+	clone := team.Clone(task)
+	clone.Audits, err = utils.NewWorkerLogger("team_logs_"+task.ID.String(), utils.GetColors()[0], 100)
+	require.NoError(t, err)
+
+	result, err := r.runTask(context.Background(), clone)
+	require.NoError(t, err)
+	return result
+}
+
+func TestRunTaskReplaysCassette(t *testing.T) {
+	t.Chdir(t.TempDir())
+	var calls atomic.Int32
+	server := httptest.NewServer(fakeLLM(&calls))
+	defer server.Close()
+	t.Setenv("LLM_BASE_URL", server.URL)
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+
+	recorded := runCassetteTask(t, models.CassetteRecord, cassette)
+	assert.Equal(t, storage.ResultSucceeded, recorded.Status)
+	assert.Equal(t, 2, recorded.Steps)
+	assert.Equal(t, "docs written", recorded.Answer)
+	served := calls.Load()
+
+	// The members and tools are listed in a different order on every run unless sorted.
+	for i := 0; i < 3; i++ {
+		replayed := runCassetteTask(t, models.CassetteReplay, cassette)
+		assert.Equal(t, served, calls.Load(), "replay must not reach the server")
+		assert.Equal(t, outcome(recorded), outcome(replayed))
+	}
+}
+
+func outcome(result storage.TaskResult) []any {
+	return []any{result.Status, result.Answer, result.Reason, result.Steps, result.Tokens}
+}
@@ -0,0 +1,236 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+)
+
+const (
+	originSchedule = "schedule"
+
+	OverlapSkip  = "skip"
+	OverlapQueue = "queue"
+)
+
+// Schedule queues a task every time its cron expression matches. The task text
+// is a template with the date of the run, e.g. "Review the logs of {{.Yesterday}}".
+type Schedule struct {
+	Name     string `yaml:"name"`
+	Cron     string `yaml:"cron"`
+	Team     string `yaml:"team"`
+	Task     string `yaml:"task"`
+	Priority int    `yaml:"priority,omitempty"`
+	// Overlap decides what happens when the previous run is still queued or
+	// running: skip the new run (default) or queue it anyway.
+	Overlap string `yaml:"overlap,omitempty"`
+}
+
+// scheduleVars are the variables available in the task template of a schedule.
+type scheduleVars struct {
+	Name      string
+	Now       time.Time
+	Date      string
+	Time      string
+	Yesterday string
+	Weekday   string
+}
+
+func (s Schedule) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("schedule name cannot be empty")
+	}
+	if s.Team == "" {
+		return fmt.Errorf("schedule %s: team cannot be empty", s.Name)
+	}
+	if _, err := parseCron(s.Cron); err != nil {
+		return fmt.Errorf("schedule %s: %w", s.Name, err)
+	}
+	if _, err := s.render(time.Now()); err != nil {
+		return fmt.Errorf("schedule %s: %w", s.Name, err)
+	}
+	switch s.Overlap {
+	case "", OverlapSkip, OverlapQueue:
+	default:
+		return fmt.Errorf("schedule %s: unknown overlap %q, expected %s or %s", s.Name, s.Overlap, OverlapSkip, OverlapQueue)
+	}
+	return nil
+}
+
+func (s Schedule) render(at time.Time) (string, error) {
+	tmpl, err := template.New(s.Name).Option("missingkey=error").Parse(s.Task)
+	if err != nil {
+		return "", fmt.Errorf("parse task template: %w", err)
+	}
+	var sb strings.Builder
+	if err = tmpl.Execute(&sb, scheduleVars{
+		Name:      s.Name,
+		Now:       at,
+		Date:      at.Format(time.DateOnly),
+		Time:      at.Format("15:04"),
+		Yesterday: at.AddDate(0, 0, -1).Format(time.DateOnly),
+		Weekday:   at.Weekday().String(),
+	}); err != nil {
+		return "", fmt.Errorf("render task template: %w", err)
+	}
+	if strings.TrimSpace(sb.String()) == "" {
+		return "", fmt.Errorf("task cannot be empty")
+	}
+	return sb.String(), nil
+}
+
+// runScheduler queues the tasks of the schedules of the team until the context is done.
+// Runs missed while the process was down are not recovered.
+func (r *Runtime) runScheduler(ctx context.Context) {
+	schedules := make([]Schedule, 0, len(r.config.Schedules))
+	crons := make([]*cronSchedule, 0, len(r.config.Schedules))
+	for _, s := range r.config.Schedules {
+		if s.Team != r.team.Name {
+			continue
+		}
+		c, err := parseCron(s.Cron)
+		if err != nil {
+			log.Printf("⚠️ Ignoring schedule %s: %v", s.Name, err)
+			continue
+		}
+		schedules = append(schedules, s)
+		crons = append(crons, c)
+	}
+	if len(schedules) == 0 {
+		return
+	}
+
+	next := make([]time.Time, len(schedules))
+	now := time.Now()
+	for i, c := range crons {
+		next[i] = c.next(now)
+		log.Printf("⏰ Schedule %s next run at %s", schedules[i].Name, next[i].Format(time.DateTime))
+	}
+
+	for {
+		var earliest time.Time
+		for _, t := range next {
+			if !t.IsZero() && (earliest.IsZero() || t.Before(earliest)) {
+				earliest = t
+			}
+		}
+		if earliest.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(earliest))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now = time.Now()
+		for i, s := range schedules {
+			if next[i].IsZero() || now.Before(next[i]) {
+				continue
+			}
+			r.triggerSchedule(ctx, s, next[i])
+			next[i] = crons[i].next(now)
+		}
+	}
+}
+
+// triggerSchedule queues the task of the schedule, unless its previous task is
+// still active and the overlap policy says to skip, and saves the result. The
+// task is queued before the run is saved, so the next tick sees it pending.
+func (r *Runtime) triggerSchedule(ctx context.Context, s Schedule, at time.Time) {
+	run := storage.ScheduleRun{Name: s.Name, Team: r.team.Name, RunAt: at}
+
+	if s.Overlap != OverlapQueue {
+		last, err := r.db.GetScheduleRun(ctx, s.Name)
+		if err != nil {
+			log.Printf("⚠️ Error reading last run of schedule %s: %v", s.Name, err)
+		}
+		switch {
+		case last == nil:
+		case last.TaskStatus == storage.TaskPending, last.TaskStatus == storage.TaskRunning,
+			last.TaskStatus == storage.TaskPaused:
+			log.Printf("⏭️ Skipping schedule %s, task %s is still %s", s.Name, last.TaskID, last.TaskStatus)
+			run.TaskID = last.TaskID
+			run.Result = storage.ScheduleSkipped
+			_ = r.db.SaveScheduleRun(ctx, run)
+			return
+		}
+	}
+
+	description, err := s.render(at)
+	if err != nil {
+		log.Printf("❌ Error rendering task of schedule %s: %v", s.Name, err)
+		run.Result = storage.ScheduleError
+		run.Error = err.Error()
+		_ = r.db.SaveScheduleRun(ctx, run)
+		return
+	}
+
+	task := &teams.Task{
+		ID:          uuid.New(),
+		Description: description,
+		Origin:      originSchedule,
+		Priority:    s.Priority,
+	}
+	if err = r.SubmitTask(ctx, task); err != nil {
+		log.Printf("❌ Error queuing task of schedule %s: %v", s.Name, err)
+		run.Result = storage.ScheduleError
+		run.Error = err.Error()
+		_ = r.db.SaveScheduleRun(ctx, run)
+		return
+	}
+	log.Printf("⏰ Schedule %s queued task %s", s.Name, task.ID.String())
+
+	run.TaskID = task.ID.String()
+	run.Result = storage.ScheduleQueued
+	_ = r.db.SaveScheduleRun(ctx, run)
+}
+
+func (r *Runtime) GetSchedulesText(ctx context.Context) string {
+	if len(r.config.Schedules) == 0 {
+		return "No schedules configured."
+	}
+	runs, err := r.db.GetScheduleRuns(ctx, r.team.Name)
+	if err != nil {
+		return "Couldn't read the schedules: " + err.Error()
+	}
+	lastRuns := make(map[string]storage.ScheduleRun, len(runs))
+	for _, run := range runs {
+		lastRuns[run.Name] = run
+	}
+
+	lines := []string{"⏰ Schedules:"}
+	for _, s := range r.config.Schedules {
+		if s.Team != r.team.Name {
+			continue
+		}
+		line := fmt.Sprintf("- %s (%s)", s.Name, s.Cron)
+		if run, ok := lastRuns[s.Name]; ok {
+			line += fmt.Sprintf(", last run %s: %s", run.RunAt.Format(time.DateTime), run.Result)
+			if run.TaskID != "" {
+				line += fmt.Sprintf(" task %s", run.TaskID)
+			}
+			if run.TaskStatus != "" {
+				line += fmt.Sprintf(" (%s)", run.TaskStatus)
+			}
+			if run.Error != "" {
+				line += ": " + run.Error
+			}
+		} else {
+			line += ", never run"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
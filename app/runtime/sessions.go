@@ -0,0 +1,170 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/tools"
+)
+
+const (
+	// maxHistory is how many messages of a session are sent as they are, the
+	// older ones are summarized once it is reached.
+	maxHistory = 33
+	// keptHistory is how many of the latest messages stay out of the summary.
+	keptHistory = 10
+	maxTokens   = -1
+)
+
+// SessionKey identifies the conversation of a user on a channel of a client.
+type SessionKey struct {
+	Origin  string
+	Channel string
+	UserID  string
+}
+
+func (k SessionKey) ID() string {
+	return fmt.Sprintf("session:%s:%s:%s", k.Origin, k.Channel, k.UserID)
+}
+
+// SaveEventOnHistory adds a message to the conversation of the session.
+func (r *Runtime) SaveEventOnHistory(ctx context.Context, key SessionKey, content, role string) error {
+	memberID := key.UserID
+	if role != models.UserRole {
+		memberID = "event_handler"
+	}
+	return r.db.SaveHistory(ctx, storage.Record{
+		TaskID:    key.ID(),
+		MemberID:  memberID,
+		Role:      role,
+		Content:   content,
+		CreatedAt: time.Now(),
+	})
+}
+
+// ProcessQuickEvent answers a message outside of any task with the event
+// handler, which sees the summary and the latest messages of the session. With
+// a toolkit the event handler can also call tools before answering.
+func (r *Runtime) ProcessQuickEvent(ctx context.Context, key SessionKey, message string,
+	toolkit map[string]tools.Tool) string {
+	handler := r.team.GetEventHandler()
+	if handler == nil {
+		return "This team has no event handler to answer messages."
+	}
+	session, history := r.loadSession(ctx, key, r.modelFor(handler))
+
+	var sessionContext string
+	if session.Summary != "" || len(history) > 0 {
+		sessionContext = fmt.Sprintf(models.SessionContextPrompt, session.Summary, formatSessionRecords(history))
+	}
+	if len(toolkit) > 0 {
+		sessionContext += models.EventHandlerToolsPrompt
+	}
+	messages := models.CreateMessages(message, handler.Prompt(sessionContext))
+	if err := r.SaveEventOnHistory(ctx, key, message, models.UserRole); err != nil {
+		log.Printf("⚠️ Error saving message of session %s: %v", key.ID(), err)
+	}
+
+	if len(toolkit) > 0 {
+		// Process saves the tool calls and the answer in the session.
+		response, err := r.modelFor(handler).Process(ctx, handler.Key, log.Default(), messages, toolkit, key.ID(), 0,
+			handler.MaxToolRounds)
+		if err != nil {
+			log.Printf("⚠️ Error processing message of session %s: %v", key.ID(), err)
+			return "Couldn't process your message. Something went wrong"
+		}
+		return response
+	}
+
+	response, err := r.modelFor(handler).Think(ctx, messages, 0.666, maxTokens)
+	if err != nil {
+		log.Printf("⚠️ Error processing message of session %s: %v", key.ID(), err)
+		return "Couldn't process your message. Something went wrong"
+	}
+	if err = r.SaveEventOnHistory(ctx, key, response, models.AssistantRole); err != nil {
+		log.Printf("⚠️ Error saving answer of session %s: %v", key.ID(), err)
+	}
+	return response
+}
+
+// ResetSession forgets the conversation of the session, the next message starts a new one.
+func (r *Runtime) ResetSession(ctx context.Context, key SessionKey) error {
+	session, err := r.getSession(ctx, key)
+	if err != nil {
+		return err
+	}
+	history, err := r.db.GetSessionHistory(ctx, session.ID, session.SinceRecordID)
+	if err != nil {
+		return err
+	}
+	if len(history) > 0 {
+		session.SinceRecordID = history[len(history)-1].ID
+	}
+	session.Summary = ""
+	session.UpdatedAt = time.Now()
+	return r.db.SaveSession(ctx, *session)
+}
+
+func (r *Runtime) getSession(ctx context.Context, key SessionKey) (*storage.Session, error) {
+	session, err := r.db.GetSession(ctx, key.ID())
+	if err != nil || session != nil {
+		return session, err
+	}
+	return &storage.Session{ID: key.ID(), Origin: key.Origin, Channel: key.Channel, UserID: key.UserID}, nil
+}
+
+// loadSession returns the session and its messages, summarizing the older ones
+// when there are too many to send.
+func (r *Runtime) loadSession(ctx context.Context, key SessionKey, model models.Interface) (*storage.Session,
+	[]storage.Record) {
+	session, err := r.getSession(ctx, key)
+	if err != nil {
+		log.Printf("⚠️ Error loading session %s: %v", key.ID(), err)
+		session = &storage.Session{ID: key.ID(), Origin: key.Origin, Channel: key.Channel, UserID: key.UserID}
+	}
+	history, err := r.db.GetSessionHistory(ctx, session.ID, session.SinceRecordID)
+	if err != nil {
+		log.Printf("⚠️ Error loading history of session %s: %v", session.ID, err)
+		return session, nil
+	}
+	if len(history) <= maxHistory {
+		return session, history
+	}
+
+	older, recent := history[:len(history)-keptHistory], history[len(history)-keptHistory:]
+	summary, err := r.summarizeSession(ctx, model, session.Summary, older)
+	if err != nil {
+		log.Printf("⚠️ Error summarizing session %s, sending the latest messages only: %v", session.ID, err)
+		return session, history[len(history)-maxHistory:]
+	}
+	session.Summary = summary
+	session.SinceRecordID = older[len(older)-1].ID
+	session.UpdatedAt = time.Now()
+	if err = r.db.SaveSession(ctx, *session); err != nil {
+		log.Printf("⚠️ Error saving summary of session %s: %v", session.ID, err)
+	}
+	log.Printf("🗜️ Session %s: %d messages summarized", session.ID, len(older))
+	return session, recent
+}
+
+func (r *Runtime) summarizeSession(ctx context.Context, model models.Interface, summary string,
+	records []storage.Record) (string, error) {
+	limit := r.config.Context.SummaryTokens
+	systemPrompt := fmt.Sprintf(models.SessionSummarySystemPrompt, limit)
+	tokens := r.availableTokens(model, systemPrompt, fmt.Sprintf(models.SessionSummaryPrompt, summary, ""))
+	messages := model.TokenEstimator().Truncate(formatSessionRecords(records), tokens)
+	userPrompt := fmt.Sprintf(models.SessionSummaryPrompt, summary, messages)
+	return model.Think(ctx, models.CreateMessages(userPrompt, systemPrompt), 0.1, limit)
+}
+
+func formatSessionRecords(records []storage.Record) string {
+	var text string
+	for _, record := range records {
+		text += fmt.Sprintf("%s Role: %s Message: %s \n", record.CreatedAt, record.Role, record.Content)
+	}
+	return text
+}
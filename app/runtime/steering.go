@@ -0,0 +1,128 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+)
+
+const userMemberKey = "user"
+
+// PauseTask suspends a running task at the next step boundary, the step in
+// progress finishes first and the task keeps its plan and history.
+func (r *Runtime) PauseTask(ctx context.Context, taskID string) error {
+	r.mu.Lock()
+	run, ok := r.running[taskID]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("task %s is not running", taskID)
+	}
+	if run.resume != nil {
+		r.mu.Unlock()
+		return fmt.Errorf("task %s is already paused", taskID)
+	}
+	run.resume = make(chan struct{})
+	r.mu.Unlock()
+
+	return r.db.UpdateTaskStatus(ctx, taskID, storage.TaskPaused)
+}
+
+// unpauseTask releases a paused task, it returns false when the task was not paused.
+func (r *Runtime) unpauseTask(ctx context.Context, taskID string) bool {
+	r.mu.Lock()
+	run, ok := r.running[taskID]
+	if !ok || run.resume == nil {
+		r.mu.Unlock()
+		return false
+	}
+	close(run.resume)
+	run.resume = nil
+	r.mu.Unlock()
+
+	if err := r.db.UpdateTaskStatus(ctx, taskID, storage.TaskRunning); err != nil {
+		log.Printf("⚠️ Error saving status of task %s: %v", taskID, err)
+	}
+	return true
+}
+
+// waitIfPaused blocks the task loop while the task is paused or until its context is done.
+func (r *Runtime) waitIfPaused(ctx context.Context, team *teams.Team, step int) {
+	task := team.Task
+	r.mu.RLock()
+	var resume chan struct{}
+	if run, ok := r.running[task.Root().ID.String()]; ok {
+		resume = run.resume
+	}
+	r.mu.RUnlock()
+	if resume == nil {
+		return
+	}
+
+	team.Audits.Printf("⏸️ Task paused after step %d", step)
+	r.publish(task, TaskPaused, TaskPausedPayload{Step: step})
+	select {
+	case <-resume:
+		team.Audits.Printf("▶️ Task resumed at step %d", step+1)
+		r.publish(task, TaskResumed, TaskResumedPayload{Step: step})
+	case <-ctx.Done():
+	}
+}
+
+// InjectMessage stores a user instruction in the task history, the leader sees
+// it on every delegation from then on.
+func (r *Runtime) InjectMessage(ctx context.Context, taskID, message string) error {
+	if message == "" {
+		return fmt.Errorf("message cannot be empty")
+	}
+	queued, err := r.db.GetQueuedTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if queued == nil || queued.Team != r.team.Name {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	if queued.IsFinished() {
+		return fmt.Errorf("task %s is already %s", taskID, queued.Status)
+	}
+
+	if err = r.db.SaveHistory(ctx, storage.Record{
+		TaskID:    taskID,
+		MemberID:  userMemberKey,
+		Role:      models.UserRole,
+		Content:   message,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	run, running := r.running[taskID]
+	r.mu.RUnlock()
+	if running {
+		run.team.Audits.Printf("💬 User instruction: %s", message)
+		r.publish(run.task, MessageInjected, MessageInjectedPayload{Message: message})
+	}
+	return nil
+}
+
+// userInstructions returns the instructions sent to the task, sub-tasks follow
+// the ones sent to the top level task.
+func (r *Runtime) userInstructions(ctx context.Context, task *teams.Task) []string {
+	records, err := r.db.GetHistoryByTaskID(ctx, task.Root().ID.String(), -1)
+	if err != nil {
+		log.Printf("⚠️ Error reading instructions of task %s: %v", task.ID.String(), err)
+		return nil
+	}
+	var instructions []string
+	for _, record := range records {
+		if record.MemberID == userMemberKey && record.Role == models.UserRole {
+			instructions = append(instructions, record.Content)
+		}
+	}
+	return instructions
+}
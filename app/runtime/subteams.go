@@ -0,0 +1,99 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/utils"
+)
+
+// runSubTeam hands the delegation to the sub-team of the member as a sub-task
+// of the current one and returns its final answer as the step result. The
+// sub-task ID is derived from the parent step, so a resumed parent continues
+// the sub-task from its checkpoint instead of starting it again.
+func (r *Runtime) runSubTeam(ctx context.Context, parent *teams.Team, d *delegation) (string, error) {
+	parentTask := parent.Task
+	description := d.action.Task
+	if d.action.Context != "" {
+		description += "\nContext:\n" + d.action.Context
+	}
+	task := &teams.Task{
+		ID:          uuid.NewSHA1(parentTask.ID, []byte(fmt.Sprintf("%s/%d", d.worker.Key, d.step))),
+		Description: description,
+		Origin:      parentTask.Origin,
+		Channel:     parentTask.Channel,
+		Priority:    parentTask.Priority,
+		Parent:      parentTask,
+	}
+	taskID := task.ID.String()
+
+	queued, err := r.db.GetQueuedTask(ctx, taskID)
+	switch {
+	case err != nil:
+		return "", err
+	case queued == nil:
+		err = r.db.EnqueueTask(ctx, storage.QueuedTask{
+			TaskID:      taskID,
+			Team:        d.worker.SubTeam.Name,
+			Description: description,
+			Origin:      task.Origin,
+			Channel:     task.Channel,
+			Priority:    task.Priority,
+			Status:      storage.TaskRunning,
+			ParentID:    parentTask.ID.String(),
+			CreatedAt:   time.Now(),
+		})
+	default:
+		err = r.db.UpdateTaskStatus(ctx, taskID, storage.TaskRunning)
+	}
+	if err != nil {
+		return "", fmt.Errorf("register sub-task: %w", err)
+	}
+
+	audits, err := utils.NewWorkerLogger("team_logs_"+taskID, utils.GetColors()[0], 10000)
+	if err != nil {
+		_ = r.db.UpdateTaskStatus(ctx, taskID, storage.TaskFailed)
+		return "", fmt.Errorf("create logger for sub-task: %w", err)
+	}
+	team := d.worker.SubTeam.Clone(task)
+	team.Audits = audits
+
+	parent.Audits.Printf("👥 Step %d handed to team %s as sub-task %s", d.step, team.Name, taskID)
+	taskResult, runErr := r.runTask(ctx, team)
+	status := taskStatus(ctx, runErr)
+	if err = r.db.UpdateTaskStatus(context.Background(), taskID, status); err != nil {
+		log.Printf("⚠️ Error saving status of sub-task %s: %v", taskID, err)
+	}
+	r.saveTaskError(taskID, runErr)
+	if runErr != nil {
+		return "", fmt.Errorf("team %s stopped as %s: %w", team.Name, status, runErr)
+	}
+
+	result := fmt.Sprintf("Team %s finished: %s", team.Name, taskResult.Reason)
+	if taskResult.Answer != "" {
+		result += "\nAnswer: " + taskResult.Answer
+	}
+	if cp, err := r.db.GetCheckpoint(ctx, taskID); err == nil && cp != nil && cp.Summary != "" {
+		result += "\nSummary:" + cp.Summary
+	}
+	parent.Audits.Printf("👥 Team %s finished step %d", team.Name, d.step)
+
+	if err = r.db.SaveHistory(ctx, storage.Record{
+		TaskID:    parentTask.ID.String(),
+		SubTaskID: int64(d.step),
+		MemberID:  d.worker.Key,
+		Role:      models.AssistantRole,
+		Content:   result,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		log.Printf("⚠️ Error saving history for task %s: %v", parentTask.ID.String(), err)
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package runtime
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"GoWorkerAI/app/tools"
+	"GoWorkerAI/app/utils"
+)
+
+// restartDelay is the pause before a supervised loop that panicked starts again.
+const restartDelay = time.Second
+
+// supervise runs the loop until the context is done, a panic is logged and
+// the loop starts again so the runtime keeps accepting tasks.
+func (r *Runtime) supervise(ctx context.Context, name string, loop func(context.Context)) {
+	for {
+		var err error
+		func() {
+			defer utils.RecoverPanic(r.team.Name+" "+name, &err)
+			loop(ctx)
+		}()
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("🔁 Restarting %s of team %s after a panic", name, r.team.Name)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(restartDelay):
+		}
+	}
+}
+
+// recoverTool turns a panic of the tool into an error returned to the model,
+// the step and the task go on.
+func recoverTool(tool tools.Tool) tools.Tool {
+	handler := tool.HandlerFunc
+	if handler == nil {
+		return tool
+	}
+	tool.HandlerFunc = func(toolTask tools.ToolTask) (result string, err error) {
+		defer utils.RecoverPanic("tool "+tool.Name, &err)
+		return handler(toolTask)
+	}
+	return tool
+}
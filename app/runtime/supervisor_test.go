@@ -0,0 +1,38 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/tools"
+	"GoWorkerAI/app/utils"
+)
+
+func TestRecoverTool(t *testing.T) {
+	tool := recoverTool(tools.Tool{Name: "explode", HandlerFunc: func(tools.ToolTask) (string, error) {
+		panic("boom")
+	}})
+
+	result, err := tool.HandlerFunc(tools.ToolTask{})
+	assert.Empty(t, result)
+	var panicErr *utils.PanicError
+	assert.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, "panic: boom", err.Error())
+	assert.NotEmpty(t, panicErr.Stack)
+}
+
+func TestSuperviseRestartsAfterPanic(t *testing.T) {
+	r := &Runtime{team: &teams.Team{Name: "test"}}
+	runs := 0
+	r.supervise(context.Background(), "loop", func(context.Context) {
+		runs++
+		if runs == 1 {
+			panic("boom")
+		}
+	})
+	assert.Equal(t, 2, runs)
+}
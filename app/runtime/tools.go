@@ -0,0 +1,66 @@
+package runtime
+
+import (
+	"context"
+
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/tools"
+)
+
+// instrumentTools wraps the toolkit of every member of a task team with the
+// runtime policies, the team must be a clone owned by the task.
+func (r *Runtime) instrumentTools(ctx context.Context, team *teams.Team) {
+	for _, member := range team.Members {
+		toolkit := member.GetToolKit()
+		if len(toolkit) == 0 {
+			continue
+		}
+
+		wrapped := make(map[string]tools.Tool, len(toolkit))
+		for name, tool := range toolkit {
+			tool = recoverTool(tool)
+			switch {
+			case name == tools.ReportIssueTool:
+				// Reporting an issue has no side effects, it only escalates to the leader and the humans.
+				tool = r.escalateIssue(ctx, team, tool)
+			case r.config.DryRun.Enabled:
+				// Nothing runs in a dry run, so there is nothing to approve either.
+				tool = r.simulateTool(ctx, team, member, tool)
+			case member.NeedsApproval(name):
+				tool = r.approvalGate(ctx, team.Task, tool)
+			}
+			tool = r.limitToolOutput(ctx, team, member, tool)
+			wrapped[name] = r.observeTool(team.Task, tool)
+		}
+		member.SetToolKit(wrapped)
+	}
+}
+
+// observeTool publishes the tool_called and tool_result events around every call.
+func (r *Runtime) observeTool(task *teams.Task, tool tools.Tool) tools.Tool {
+	handler := tool.HandlerFunc
+	if handler == nil {
+		return tool
+	}
+	tool.HandlerFunc = func(toolTask tools.ToolTask) (string, error) {
+		r.publish(task, ToolCalled, ToolCalledPayload{
+			Step:      toolTask.StepID,
+			Member:    toolTask.MemberKey,
+			Tool:      tool.Name,
+			Arguments: toolTask.Parameters,
+		})
+		result, err := handler(toolTask)
+		payload := ToolResultPayload{
+			Step:   toolTask.StepID,
+			Member: toolTask.MemberKey,
+			Tool:   tool.Name,
+			Result: result,
+		}
+		if err != nil {
+			payload.Error = err.Error()
+		}
+		r.publish(task, ToolResult, payload)
+		return result, err
+	}
+	return tool
+}
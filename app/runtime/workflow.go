@@ -0,0 +1,115 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"GoWorkerAI/app/models"
+	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
+	"GoWorkerAI/app/utils"
+)
+
+// errWorkflowStep marks the workflow failures that can fall back to leader mode.
+var errWorkflowStep = errors.New("workflow step failed")
+
+// runWorkflow executes the team workflow in order, passing the outputs of the
+// previous steps to the next ones. Each executed step gets its own step ID, so
+// the history is recorded the same way as in leader mode. Interrupted workflows
+// start again from the first step. The steps and the last output are set on the
+// result, also when a step fails.
+func (r *Runtime) runWorkflow(ctx context.Context, team *teams.Team, budget *budgetTracker,
+	result *storage.TaskResult) error {
+	task := team.Task
+	workflow := team.Workflow
+	outputs := make(map[string]string, len(workflow.Steps))
+	runs := make(map[string]int, len(workflow.Steps))
+	step := 0
+	defer func() { result.Steps = step }()
+
+	team.Audits.Printf("🧭 Running workflow with %d steps", len(workflow.Steps))
+	for i := 0; i < len(workflow.Steps); {
+		ws := workflow.Steps[i]
+		r.waitIfPaused(ctx, team, step)
+		if err := budget.check(step); err != nil {
+			r.exhaustBudget(team, err)
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			team.Audits.Printf("🛑 Task %s stopped: %v", task.ID.String(), err)
+			return err
+		}
+
+		vars := teams.WorkflowVars{Task: task.Description, Outputs: outputs, Iteration: runs[ws.Name]}
+		run, err := ws.ShouldRun(vars)
+		if err != nil {
+			return fmt.Errorf("%w: %s condition: %v", errWorkflowStep, ws.Name, err)
+		}
+		if !run {
+			team.Audits.Printf("⏭️ Skipping workflow step %s, condition not met", ws.Name)
+			i++
+			continue
+		}
+		instruction, err := ws.Render(vars)
+		if err != nil {
+			return fmt.Errorf("%w: %s instruction: %v", errWorkflowStep, ws.Name, err)
+		}
+		if instructions := r.userInstructions(ctx, task); len(instructions) > 0 {
+			instruction += fmt.Sprintf(models.UserInstructionsPrompt, "- "+strings.Join(instructions, "\n- "))
+		}
+
+		step++
+		d := &delegation{
+			step:   step,
+			action: models.DelegateAction{Worker: ws.Member, Task: instruction, Step: i + 1, Context: ws.Context(outputs)},
+			worker: team.GetMember(ws.Member),
+		}
+		team.Audits.Printf("✅ Workflow step %s (step %d) assigned to %s", ws.Name, step, ws.Member)
+		r.publish(task, StepDelegated, StepDelegatedPayload{
+			Step:     step,
+			PlanStep: i + 1,
+			Worker:   ws.Member,
+			Task:     instruction,
+		})
+		r.processDelegation(ctx, team, task, d)
+		if d.err == nil {
+			if issues := r.reportedIssues(ctx, task, step); len(issues) > 0 {
+				d.err = fmt.Errorf("issue reported: %s", strings.Join(issues, "; "))
+			}
+		}
+		if d.err != nil {
+			budget.fail()
+			return fmt.Errorf("%w: %s: %v", errWorkflowStep, ws.Name, d.err)
+		}
+		budget.succeed()
+
+		outputs[ws.Name] = d.output
+		result.Answer = d.output
+		runs[ws.Name]++
+		team.Audits.Printf("📍 Workflow step %s done (run %d)", ws.Name, runs[ws.Name])
+
+		if ws.Loop != nil {
+			vars.Output = d.output
+			again, err := ws.Loop.Again(ws.Name, vars, runs[ws.Name])
+			if err != nil {
+				return fmt.Errorf("%w: %s loop: %v", errWorkflowStep, ws.Name, err)
+			}
+			if again {
+				to := i
+				if ws.Loop.To != "" {
+					to = workflow.Index(ws.Loop.To)
+				}
+				team.Audits.Printf("🔁 Workflow loops back to %s", workflow.Steps[to].Name)
+				i = to
+				continue
+			}
+		}
+		i++
+	}
+
+	team.Audits.Printf("✅ Workflow finished")
+	result.Reason = "workflow finished: " + utils.Truncate(result.Answer, 500)
+	return nil
+}
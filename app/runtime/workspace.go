@@ -0,0 +1,129 @@
+package runtime
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"GoWorkerAI/app/storage"
+)
+
+// maxWorkspaceFiles bounds the snapshot of a huge workspace, the files past it
+// are not reported.
+const maxWorkspaceFiles = 20000
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// snapshotWorkspace returns the state of the files of the workspace, skipping
+// hidden folders and dependencies. It returns nil without a workspace.
+func snapshotWorkspace(dir string) map[string]fileState {
+	if dir == "" {
+		return nil
+	}
+	files := make(map[string]fileState)
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if path != dir && (strings.HasPrefix(entry.Name(), ".") || entry.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(files) >= maxWorkspaceFiles {
+			return filepath.SkipAll
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		files[filepath.ToSlash(rel)] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	return files
+}
+
+// workspaceChanges lists the files created, modified or deleted between two snapshots.
+func workspaceChanges(before, after map[string]fileState) []storage.FileChange {
+	if before == nil || after == nil {
+		return nil
+	}
+	var changes []storage.FileChange
+	for path, state := range after {
+		previous, ok := before[path]
+		switch {
+		case !ok:
+			changes = append(changes, storage.FileChange{Path: path, Change: storage.FileCreated})
+		case previous.size != state.size || !previous.modTime.Equal(state.modTime):
+			changes = append(changes, storage.FileChange{Path: path, Change: storage.FileModified})
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changes = append(changes, storage.FileChange{Path: path, Change: storage.FileDeleted})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// workspaceRuns tracks the tasks changing each workspace. Every team shares it,
+// their runtimes may share the same folder.
+var workspaceRuns = &workspaceTracker{active: make(map[string][]*workspaceRun)}
+
+type workspaceTracker struct {
+	mu     sync.Mutex
+	active map[string][]*workspaceRun
+}
+
+// workspaceRun is a task changing the workspace, shared once another task
+// changed it at the same time, then the diff of the workspace is not only its own.
+type workspaceRun struct {
+	dir    string
+	root   string
+	shared bool
+}
+
+// begin registers a task on the workspace, the sub-tasks of the same root task
+// don't share it with each other.
+func (t *workspaceTracker) begin(dir, root string) *workspaceRun {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	run := &workspaceRun{dir: dir, root: root}
+	for _, other := range t.active[dir] {
+		if other.root != root {
+			other.shared = true
+			run.shared = true
+		}
+	}
+	t.active[dir] = append(t.active[dir], run)
+	return run
+}
+
+// end unregisters the task and reports whether another one changed the workspace meanwhile.
+func (t *workspaceTracker) end(run *workspaceRun) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	runs := t.active[run.dir]
+	for i, other := range runs {
+		if other == run {
+			t.active[run.dir] = append(runs[:i], runs[i+1:]...)
+			break
+		}
+	}
+	if len(t.active[run.dir]) == 0 {
+		delete(t.active, run.dir)
+	}
+	return run.shared
+}
@@ -0,0 +1,61 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoWorkerAI/app/storage"
+)
+
+func TestWorkspaceChanges(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	write("keep.txt", "same")
+	write("edit.txt", "before")
+	write("remove.txt", "gone")
+	write(".git/HEAD", "ref")
+
+	before := snapshotWorkspace(dir)
+	assert.Len(t, before, 3)
+
+	write("edit.txt", "after, longer")
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, "edit.txt"), time.Now(), time.Now().Add(time.Second)))
+	assert.NoError(t, os.Remove(filepath.Join(dir, "remove.txt")))
+	write("src/new.go", "package src")
+	write(".git/HEAD", "other ref")
+
+	assert.Equal(t, []storage.FileChange{
+		{Path: "edit.txt", Change: storage.FileModified},
+		{Path: "remove.txt", Change: storage.FileDeleted},
+		{Path: "src/new.go", Change: storage.FileCreated},
+	}, workspaceChanges(before, snapshotWorkspace(dir)))
+
+	assert.Nil(t, snapshotWorkspace(""))
+	assert.Nil(t, workspaceChanges(nil, snapshotWorkspace(dir)))
+}
+
+func TestWorkspaceTracker(t *testing.T) {
+	tracker := &workspaceTracker{active: make(map[string][]*workspaceRun)}
+
+	alone := tracker.begin("ws", "a")
+	assert.False(t, tracker.end(alone))
+
+	parent := tracker.begin("ws", "a")
+	child := tracker.begin("ws", "a")
+	other := tracker.begin("other", "b")
+	assert.False(t, tracker.end(child), "sub-tasks of the same task don't share the workspace")
+	assert.False(t, tracker.end(other))
+
+	concurrent := tracker.begin("ws", "b")
+	assert.True(t, tracker.end(concurrent))
+	assert.True(t, tracker.end(parent), "a task stays shared after the other one ended")
+	assert.Empty(t, tracker.active)
+}
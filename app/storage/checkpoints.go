@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+func (s *SQLiteContextStorage) SaveCheckpoint(ctx context.Context, checkpoint Checkpoint) error {
+	if checkpoint.UpdatedAt.IsZero() {
+		checkpoint.UpdatedAt = time.Now()
+	}
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO checkpoints (task_id, plan, step, summarized_records, summary, updated_at)
+                 VALUES (?, ?, ?, ?, ?, datetime(?))
+                 ON CONFLICT(task_id) DO UPDATE SET
+                     plan = excluded.plan,
+                     step = excluded.step,
+                     summarized_records = excluded.summarized_records,
+                     summary = excluded.summary,
+                     updated_at = excluded.updated_at`,
+		checkpoint.TaskID, checkpoint.Plan, checkpoint.Step, checkpoint.SummarizedRecords, checkpoint.Summary,
+		checkpoint.UpdatedAt.Format(timeLayout),
+	)
+	if err != nil {
+		log.Printf("⚠️ Error saving checkpoint for task %s: %v", checkpoint.TaskID, err)
+		return err
+	}
+	return nil
+}
+
+func (s *SQLiteContextStorage) GetCheckpoint(ctx context.Context, taskID string) (*Checkpoint, error) {
+	var checkpoint Checkpoint
+	var updatedAt string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT task_id, plan, step, summarized_records, summary, updated_at FROM checkpoints WHERE task_id = ?`,
+		taskID,
+	).Scan(&checkpoint.TaskID, &checkpoint.Plan, &checkpoint.Step, &checkpoint.SummarizedRecords,
+		&checkpoint.Summary, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkpoint.UpdatedAt = parseTime(updatedAt)
+	return &checkpoint, nil
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const issueColumns = `id, task_id, team, step_id, member_id, severity, reason, status, answer, answered_by, created_at,
+    answered_at`
+
+func (s *SQLiteContextStorage) SaveIssue(ctx context.Context, issue Issue) (int64, error) {
+	if issue.Status == "" {
+		issue.Status = IssueOpen
+	}
+	if issue.CreatedAt.IsZero() {
+		issue.CreatedAt = time.Now()
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO issues (task_id, team, step_id, member_id, severity, reason, status, created_at)
+                 VALUES (?, ?, ?, ?, ?, ?, ?, datetime(?))`,
+		issue.TaskID, issue.Team, issue.StepID, issue.MemberID, issue.Severity, issue.Reason, issue.Status,
+		issue.CreatedAt.Format(timeLayout),
+	)
+	if err != nil {
+		log.Printf("⚠️ Error saving issue of task %s: %v", issue.TaskID, err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (s *SQLiteContextStorage) GetIssue(ctx context.Context, id int64) (*Issue, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+issueColumns+` FROM issues WHERE id = ?`, id)
+	issue, err := scanIssue(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return issue, err
+}
+
+func (s *SQLiteContextStorage) GetTaskIssues(ctx context.Context, taskID string) ([]Issue, error) {
+	return s.queryIssues(ctx, `SELECT `+issueColumns+` FROM issues WHERE task_id = ? ORDER BY id`, taskID)
+}
+
+func (s *SQLiteContextStorage) GetOpenIssues(ctx context.Context, team string) ([]Issue, error) {
+	return s.queryIssues(ctx, `SELECT `+issueColumns+` FROM issues WHERE team = ? AND status = ? ORDER BY id`,
+		team, IssueOpen)
+}
+
+// AnswerIssue stores the answer of a human, an issue can only be answered once.
+func (s *SQLiteContextStorage) AnswerIssue(ctx context.Context, id int64, answer, answeredBy string) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE issues SET status = ?, answer = ?, answered_by = ?, answered_at = datetime(?)
+        WHERE id = ? AND status = ?`,
+		IssueAnswered, answer, answeredBy, time.Now().Format(timeLayout), id, IssueOpen)
+	if err != nil {
+		log.Printf("⚠️ Error answering issue %d: %v", id, err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("issue %d not found or already answered", id)
+	}
+	return nil
+}
+
+func (s *SQLiteContextStorage) queryIssues(ctx context.Context, query string, args ...any) ([]Issue, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issues []Issue
+	for rows.Next() {
+		issue, err := scanIssue(rows)
+		if err != nil {
+			log.Printf("⚠️ Error scanning issue: %v", err)
+			continue
+		}
+		issues = append(issues, *issue)
+	}
+	return issues, rows.Err()
+}
+
+func scanIssue(row rowScanner) (*Issue, error) {
+	var issue Issue
+	var answer, answeredBy, createdAt, answeredAt sql.NullString
+	if err := row.Scan(&issue.ID, &issue.TaskID, &issue.Team, &issue.StepID, &issue.MemberID, &issue.Severity,
+		&issue.Reason, &issue.Status, &answer, &answeredBy, &createdAt, &answeredAt); err != nil {
+		return nil, err
+	}
+	issue.Answer = answer.String
+	issue.AnsweredBy = answeredBy.String
+	issue.CreatedAt = parseTime(createdAt.String)
+	issue.AnsweredAt = parseTime(answeredAt.String)
+	return &issue, nil
+}
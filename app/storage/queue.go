@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	timeLayout = "2006-01-02 15:04:05"
+
+	queueColumns = `task_id, team, description, origin, channel, priority, budget, status, parent_id, error, created_at,
+    started_at, finished_at`
+)
+
+func (s *SQLiteContextStorage) EnqueueTask(ctx context.Context, task QueuedTask) error {
+	if task.Status == "" {
+		task.Status = TaskPending
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO task_queue (task_id, team, description, origin, channel, priority, budget, status, parent_id,
+                 created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime(?))`,
+		task.TaskID, task.Team, task.Description, task.Origin, task.Channel, task.Priority, task.Budget, task.Status,
+		task.ParentID, task.CreatedAt.Format(timeLayout),
+	)
+	if err != nil {
+		log.Printf("⚠️ Error enqueuing task %s: %v", task.TaskID, err)
+		return err
+	}
+	log.Printf("📥 Task queued: %s (team: %s, priority: %d)", task.TaskID, task.Team, task.Priority)
+	return nil
+}
+
+// ClaimNextTask marks the next pending task of the team as running and returns it.
+// It returns nil when the queue is empty.
+func (s *SQLiteContextStorage) ClaimNextTask(ctx context.Context, team string, byPriority bool) (*QueuedTask, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order := " ORDER BY created_at ASC, rowid ASC"
+	if byPriority {
+		order = " ORDER BY priority DESC, created_at ASC, rowid ASC"
+	}
+	row := tx.QueryRowContext(ctx,
+		`SELECT `+queueColumns+` FROM task_queue
+        WHERE team = ? AND status = ? AND COALESCE(parent_id, '') = ''`+order+` LIMIT 1`,
+		team, TaskPending)
+
+	task, err := scanQueuedTask(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if _, err = tx.ExecContext(ctx,
+		`UPDATE task_queue SET status = ?, started_at = datetime(?) WHERE task_id = ?`,
+		TaskRunning, now.Format(timeLayout), task.TaskID); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	task.Status = TaskRunning
+	task.StartedAt = now
+	return task, nil
+}
+
+func (s *SQLiteContextStorage) UpdateTaskStatus(ctx context.Context, taskID, status string) error {
+	query := `UPDATE task_queue SET status = ?, finished_at = NULL, error = NULL WHERE task_id = ?`
+	args := []any{status, taskID}
+	if (QueuedTask{Status: status}).IsFinished() {
+		query = `UPDATE task_queue SET status = ?, finished_at = datetime(?) WHERE task_id = ?`
+		args = []any{status, time.Now().Format(timeLayout), taskID}
+	}
+
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("⚠️ Error updating status of task %s: %v", taskID, err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("task " + taskID + " not found in queue")
+	}
+	return nil
+}
+
+// SaveTaskError stores why the task did not complete.
+func (s *SQLiteContextStorage) SaveTaskError(ctx context.Context, taskID, message string) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE task_queue SET error = ? WHERE task_id = ?`, message, taskID); err != nil {
+		log.Printf("⚠️ Error saving error of task %s: %v", taskID, err)
+		return err
+	}
+	return nil
+}
+
+// MarkRunningTasks moves the tasks left running or paused by a previous process to the given status,
+// sub-tasks are always marked as interrupted since they only continue with their parent.
+func (s *SQLiteContextStorage) MarkRunningTasks(ctx context.Context, team, status string) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE task_queue SET started_at = NULL,
+            status = CASE WHEN COALESCE(parent_id, '') = '' THEN ? ELSE ? END
+        WHERE team = ? AND status IN (?, ?)`,
+		status, TaskInterrupted, team, TaskRunning, TaskPaused)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *SQLiteContextStorage) GetQueuedTask(ctx context.Context, taskID string) (*QueuedTask, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+queueColumns+` FROM task_queue WHERE task_id = ?`, taskID)
+	task, err := scanQueuedTask(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return task, err
+}
+
+func (s *SQLiteContextStorage) GetQueuedTasks(ctx context.Context, team string, statuses ...string) ([]QueuedTask, error) {
+	query := `SELECT ` + queueColumns + ` FROM task_queue WHERE team = ?`
+	args := []any{team}
+	if len(statuses) > 0 {
+		query += " AND status IN (?" + strings.Repeat(", ?", len(statuses)-1) + ")"
+		for _, status := range statuses {
+			args = append(args, status)
+		}
+	}
+	query += " ORDER BY created_at ASC, rowid ASC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []QueuedTask
+	for rows.Next() {
+		task, err := scanQueuedTask(rows)
+		if err != nil {
+			log.Printf("⚠️ Error scanning queued task for team %s: %v", team, err)
+			continue
+		}
+		tasks = append(tasks, *task)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (s *SQLiteContextStorage) GetSubTasks(ctx context.Context, parentID string) ([]QueuedTask, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+queueColumns+` FROM task_queue WHERE parent_id = ? ORDER BY created_at ASC, rowid ASC`, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []QueuedTask
+	for rows.Next() {
+		task, err := scanQueuedTask(rows)
+		if err != nil {
+			log.Printf("⚠️ Error scanning sub-task of %s: %v", parentID, err)
+			continue
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanQueuedTask(row rowScanner) (*QueuedTask, error) {
+	var task QueuedTask
+	var origin, channel, budget, parentID, taskErr, createdAt, startedAt, finishedAt sql.NullString
+	if err := row.Scan(&task.TaskID, &task.Team, &task.Description, &origin, &channel, &task.Priority,
+		&budget, &task.Status, &parentID, &taskErr, &createdAt, &startedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	task.ParentID = parentID.String
+	task.Error = taskErr.String
+	task.Origin = origin.String
+	task.Channel = channel.String
+	task.Budget = budget.String
+	task.CreatedAt = parseTime(createdAt.String)
+	task.StartedAt = parseTime(startedAt.String)
+	task.FinishedAt = parseTime(finishedAt.String)
+	return &task, nil
+}
+
+// parseTime accepts both the layout used on insert and the RFC3339 form
+// returned by the driver for TIMESTAMP columns.
+func parseTime(value string) time.Time {
+	for _, layout := range []string{time.RFC3339, timeLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
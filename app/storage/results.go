@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+)
+
+// SaveTaskResult stores the result of a task, a resumed task replaces the
+// result of its previous run.
+func (s *SQLiteContextStorage) SaveTaskResult(ctx context.Context, result TaskResult) error {
+	files, err := json.Marshal(result.Files)
+	if err != nil {
+		return err
+	}
+	if result.FinishedAt.IsZero() {
+		result.FinishedAt = time.Now()
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO tasks (task_id, team, description, status, answer, reason, steps, tokens, duration_ms, files, error,
+                 started_at, finished_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime(?), datetime(?))
+                 ON CONFLICT(task_id) DO UPDATE SET
+                     status = excluded.status,
+                     answer = excluded.answer,
+                     reason = excluded.reason,
+                     steps = excluded.steps,
+                     tokens = excluded.tokens,
+                     duration_ms = excluded.duration_ms,
+                     files = excluded.files,
+                     error = excluded.error,
+                     started_at = excluded.started_at,
+                     finished_at = excluded.finished_at`,
+		result.TaskID, result.Team, result.Description, result.Status, result.Answer, result.Reason, result.Steps,
+		result.Tokens, result.Duration.Milliseconds(), string(files), result.Error,
+		result.StartedAt.Format(timeLayout), result.FinishedAt.Format(timeLayout),
+	)
+	if err != nil {
+		log.Printf("⚠️ Error saving result of task %s: %v", result.TaskID, err)
+		return err
+	}
+	return nil
+}
+
+func (s *SQLiteContextStorage) GetTaskResult(ctx context.Context, taskID string) (*TaskResult, error) {
+	var result TaskResult
+	var durationMs int64
+	var files string
+	var taskErr, startedAt, finishedAt sql.NullString
+	err := s.db.QueryRowContext(ctx,
+		`SELECT task_id, team, description, status, answer, reason, steps, tokens, duration_ms, files, error,
+            started_at, finished_at FROM tasks WHERE task_id = ?`, taskID,
+	).Scan(&result.TaskID, &result.Team, &result.Description, &result.Status, &result.Answer, &result.Reason,
+		&result.Steps, &result.Tokens, &durationMs, &files, &taskErr, &startedAt, &finishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal([]byte(files), &result.Files); err != nil {
+		log.Printf("⚠️ Invalid files in result of task %s: %v", taskID, err)
+	}
+	result.Duration = time.Duration(durationMs) * time.Millisecond
+	result.Error = taskErr.String
+	result.StartedAt = parseTime(startedAt.String)
+	result.FinishedAt = parseTime(finishedAt.String)
+	return &result, nil
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+)
+
+const scheduleRunColumns = `s.name, s.team, s.run_at, COALESCE(s.task_id, ''), s.result, COALESCE(s.error, ''),
+    COALESCE(q.status, '')`
+
+func (s *SQLiteContextStorage) SaveScheduleRun(ctx context.Context, run ScheduleRun) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO schedule_runs (name, team, run_at, task_id, result, error)
+                 VALUES (?, ?, datetime(?), ?, ?, ?)
+                 ON CONFLICT(name) DO UPDATE SET
+                     team = excluded.team,
+                     run_at = excluded.run_at,
+                     task_id = excluded.task_id,
+                     result = excluded.result,
+                     error = excluded.error`,
+		run.Name, run.Team, run.RunAt.Format(timeLayout), run.TaskID, run.Result, run.Error,
+	)
+	if err != nil {
+		log.Printf("⚠️ Error saving run of schedule %s: %v", run.Name, err)
+		return err
+	}
+	return nil
+}
+
+func (s *SQLiteContextStorage) GetScheduleRun(ctx context.Context, name string) (*ScheduleRun, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+scheduleRunColumns+` FROM schedule_runs s
+        LEFT JOIN task_queue q ON q.task_id = s.task_id WHERE s.name = ?`, name)
+	run, err := scanScheduleRun(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return run, err
+}
+
+func (s *SQLiteContextStorage) GetScheduleRuns(ctx context.Context, team string) ([]ScheduleRun, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+scheduleRunColumns+` FROM schedule_runs s
+        LEFT JOIN task_queue q ON q.task_id = s.task_id WHERE s.team = ? ORDER BY s.name`, team)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []ScheduleRun
+	for rows.Next() {
+		run, err := scanScheduleRun(rows)
+		if err != nil {
+			log.Printf("⚠️ Error scanning schedule run for team %s: %v", team, err)
+			continue
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+func scanScheduleRun(row rowScanner) (*ScheduleRun, error) {
+	var run ScheduleRun
+	var runAt string
+	if err := row.Scan(&run.Name, &run.Team, &runAt, &run.TaskID, &run.Result, &run.Error,
+		&run.TaskStatus); err != nil {
+		return nil, err
+	}
+	run.RunAt = parseTime(runAt)
+	return &run, nil
+}
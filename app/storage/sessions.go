@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+func (s *SQLiteContextStorage) GetSession(ctx context.Context, id string) (*Session, error) {
+	var session Session
+	var updatedAt sql.NullString
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, origin, channel, user_id, summary, since_record_id, updated_at FROM sessions WHERE id = ?`, id,
+	).Scan(&session.ID, &session.Origin, &session.Channel, &session.UserID, &session.Summary, &session.SinceRecordID,
+		&updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	session.UpdatedAt = parseTime(updatedAt.String)
+	return &session, nil
+}
+
+func (s *SQLiteContextStorage) SaveSession(ctx context.Context, session Session) error {
+	if session.UpdatedAt.IsZero() {
+		session.UpdatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO sessions (id, origin, channel, user_id, summary, since_record_id, updated_at)
+                 VALUES (?, ?, ?, ?, ?, ?, datetime(?))
+                 ON CONFLICT(id) DO UPDATE SET
+                     summary = excluded.summary,
+                     since_record_id = excluded.since_record_id,
+                     updated_at = excluded.updated_at`,
+		session.ID, session.Origin, session.Channel, session.UserID, session.Summary, session.SinceRecordID,
+		session.UpdatedAt.Format(timeLayout),
+	)
+	if err != nil {
+		log.Printf("⚠️ Error saving session %s: %v", session.ID, err)
+		return err
+	}
+	return nil
+}
+
+// GetSessionHistory returns the messages of the session after the given record, oldest first.
+func (s *SQLiteContextStorage) GetSessionHistory(ctx context.Context, id string, sinceRecordID int64) ([]Record, error) {
+	return s.queryRecords(ctx, `SELECT `+recordColumns+` FROM records WHERE task_id = ? AND id > ? ORDER BY id ASC`,
+		id, sinceRecordID)
+}
@@ -6,13 +6,15 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"time"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
 
 var _ Interface = &SQLiteContextStorage{}
 
+const recordColumns = `id, task_id, member_id, step_id, role, content, tool, parameters, created_at`
+
 type SQLiteContextStorage struct {
 	db *sql.DB
 }
@@ -40,6 +42,8 @@ func NewSQLiteStorage() *SQLiteContextStorage {
 	if err != nil {
 		log.Fatalf("❌ Error opening SQLite DB at %s: %v", dbPath, err)
 	}
+	// SQLite allows a single writer, parallel steps share one connection to avoid SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
 
 	_, err = db.Exec(`
         CREATE TABLE IF NOT EXISTS records (
@@ -54,6 +58,77 @@ func NewSQLiteStorage() *SQLiteContextStorage {
             created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
         );
         CREATE INDEX IF NOT EXISTS idx_task_id ON records (task_id);
+        CREATE TABLE IF NOT EXISTS task_queue (
+            task_id TEXT PRIMARY KEY,
+            team TEXT NOT NULL,
+            description TEXT NOT NULL,
+            origin TEXT NULL,
+            channel TEXT NULL,
+            priority INTEGER NOT NULL DEFAULT 0,
+            budget TEXT NULL,
+            status TEXT NOT NULL,
+            parent_id TEXT NULL,
+            error TEXT NULL,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            started_at TIMESTAMP NULL,
+            finished_at TIMESTAMP NULL
+        );
+        CREATE INDEX IF NOT EXISTS idx_task_queue_status ON task_queue (team, status);
+        CREATE TABLE IF NOT EXISTS checkpoints (
+            task_id TEXT PRIMARY KEY,
+            plan TEXT NOT NULL,
+            step INTEGER NOT NULL DEFAULT 0,
+            summarized_records INTEGER NOT NULL DEFAULT 0,
+            summary TEXT NOT NULL DEFAULT '',
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE IF NOT EXISTS schedule_runs (
+            name TEXT PRIMARY KEY,
+            team TEXT NOT NULL,
+            run_at TIMESTAMP NOT NULL,
+            task_id TEXT NULL,
+            result TEXT NOT NULL,
+            error TEXT NULL
+        );
+        CREATE TABLE IF NOT EXISTS tasks (
+            task_id TEXT PRIMARY KEY,
+            team TEXT NOT NULL,
+            description TEXT NOT NULL,
+            status TEXT NOT NULL,
+            answer TEXT NOT NULL DEFAULT '',
+            reason TEXT NOT NULL DEFAULT '',
+            steps INTEGER NOT NULL DEFAULT 0,
+            tokens INTEGER NOT NULL DEFAULT 0,
+            duration_ms INTEGER NOT NULL DEFAULT 0,
+            files TEXT NOT NULL DEFAULT '[]',
+            error TEXT NULL,
+            started_at TIMESTAMP NULL,
+            finished_at TIMESTAMP NULL
+        );
+        CREATE TABLE IF NOT EXISTS sessions (
+            id TEXT PRIMARY KEY,
+            origin TEXT NOT NULL,
+            channel TEXT NOT NULL,
+            user_id TEXT NOT NULL,
+            summary TEXT NOT NULL DEFAULT '',
+            since_record_id INTEGER NOT NULL DEFAULT 0,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE TABLE IF NOT EXISTS issues (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            task_id TEXT NOT NULL,
+            team TEXT NOT NULL,
+            step_id INTEGER NOT NULL DEFAULT 0,
+            member_id TEXT NOT NULL,
+            severity TEXT NOT NULL,
+            reason TEXT NOT NULL,
+            status TEXT NOT NULL,
+            answer TEXT NULL,
+            answered_by TEXT NULL,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            answered_at TIMESTAMP NULL
+        );
+        CREATE INDEX IF NOT EXISTS idx_issues_task_id ON issues (task_id);
     `)
 	if err != nil {
 		log.Fatalf("❌ Error creating table: %v", err)
@@ -72,7 +147,7 @@ func (s *SQLiteContextStorage) SaveHistory(ctx context.Context, record Record) e
 	res, err := tx.ExecContext(ctx,
 		`INSERT INTO records (task_id, step_id, member_id, role, content, tool, parameters, created_at)
                  VALUES (?, ?, ?, ?, ?, ?, ?, datetime(?))`,
-		record.TaskID, record.SubTaskID, record.MemberID, record.Role, record.Content, record.Tool, record.Parameters, record.CreatedAt.Format("2006-01-02 15:04:05"),
+		record.TaskID, record.SubTaskID, record.MemberID, record.Role, record.Content, record.Tool, record.Parameters, record.CreatedAt.Format(timeLayout),
 	)
 	if err != nil {
 		log.Printf("⚠️ Error saving record for task %s: %v", record.TaskID, err)
@@ -92,6 +167,45 @@ func (s *SQLiteContextStorage) SaveHistory(ctx context.Context, record Record) e
 	return nil
 }
 
+// SearchHistory returns the latest task records that contain the query, newest
+// first, within a single task when taskID is set. Conversation sessions are not searched.
+func (s *SQLiteContextStorage) SearchHistory(ctx context.Context, query, taskID string, limit int) ([]Record, error) {
+	sqlQuery := `SELECT ` + recordColumns + ` FROM records
+        WHERE content LIKE ? ESCAPE '\' AND task_id NOT LIKE 'session:%'`
+	args := []any{"%" + likeEscaper.Replace(query) + "%"}
+	if taskID != "" {
+		sqlQuery += " AND task_id = ?"
+		args = append(args, taskID)
+	}
+	sqlQuery += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+	return s.queryRecords(ctx, sqlQuery, args...)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (s *SQLiteContextStorage) queryRecords(ctx context.Context, query string, args ...any) ([]Record, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var it Record
+		var createdAt string
+		if err = rows.Scan(&it.ID, &it.TaskID, &it.MemberID, &it.SubTaskID, &it.Role, &it.Content, &it.Tool,
+			&it.Parameters, &createdAt); err != nil {
+			log.Printf("⚠️ Error scanning record: %v", err)
+			continue
+		}
+		it.CreatedAt = parseTime(createdAt)
+		records = append(records, it)
+	}
+	return records, rows.Err()
+}
+
 func (s *SQLiteContextStorage) GetHistoryByTaskID(ctx context.Context, taskID string, stepID int) ([]Record, error) {
 	query := `
          SELECT id, task_id, member_id, step_id, role, content, tool, parameters, created_at
@@ -119,7 +233,7 @@ func (s *SQLiteContextStorage) GetHistoryByTaskID(ctx context.Context, taskID st
 			log.Printf("⚠️ Error scanning row for task %s: %v", taskID, err)
 			continue
 		}
-		it.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
+		it.CreatedAt = parseTime(createdAt)
 		history = append(history, it)
 	}
 	if err = rows.Err(); err != nil {
@@ -6,9 +6,75 @@ import (
 	"time"
 )
 
+const (
+	TaskPending     = "pending"
+	TaskRunning     = "running"
+	TaskCompleted   = "completed"
+	TaskFailed      = "failed"
+	TaskCancelled   = "cancelled"
+	TaskPaused      = "paused"
+	TaskInterrupted = "interrupted"
+	TaskBudgetOut   = "budget_exhausted"
+)
+
+const (
+	ScheduleQueued  = "queued"
+	ScheduleSkipped = "skipped"
+	ScheduleError   = "error"
+)
+
+const (
+	IssueOpen     = "open"
+	IssueAnswered = "answered"
+)
+
+// Statuses of a task result, the tasks that did not succeed keep the status of the queue.
+const (
+	ResultSucceeded       = "succeeded"
+	ResultFailed          = TaskFailed
+	ResultCancelled       = TaskCancelled
+	ResultBudgetExhausted = TaskBudgetOut
+)
+
+const (
+	FileCreated  = "created"
+	FileModified = "modified"
+	FileDeleted  = "deleted"
+)
+
 type Interface interface {
 	SaveHistory(ctx context.Context, iteration Record) error
 	GetHistoryByTaskID(ctx context.Context, taskID string, stepID int) ([]Record, error)
+	SearchHistory(ctx context.Context, query, taskID string, limit int) ([]Record, error)
+
+	EnqueueTask(ctx context.Context, task QueuedTask) error
+	ClaimNextTask(ctx context.Context, team string, byPriority bool) (*QueuedTask, error)
+	UpdateTaskStatus(ctx context.Context, taskID, status string) error
+	MarkRunningTasks(ctx context.Context, team, status string) (int64, error)
+	GetQueuedTask(ctx context.Context, taskID string) (*QueuedTask, error)
+	GetQueuedTasks(ctx context.Context, team string, statuses ...string) ([]QueuedTask, error)
+	GetSubTasks(ctx context.Context, parentID string) ([]QueuedTask, error)
+	SaveTaskError(ctx context.Context, taskID, message string) error
+
+	SaveCheckpoint(ctx context.Context, checkpoint Checkpoint) error
+	GetCheckpoint(ctx context.Context, taskID string) (*Checkpoint, error)
+
+	SaveScheduleRun(ctx context.Context, run ScheduleRun) error
+	GetScheduleRun(ctx context.Context, name string) (*ScheduleRun, error)
+	GetScheduleRuns(ctx context.Context, team string) ([]ScheduleRun, error)
+
+	SaveTaskResult(ctx context.Context, result TaskResult) error
+	GetTaskResult(ctx context.Context, taskID string) (*TaskResult, error)
+
+	GetSession(ctx context.Context, id string) (*Session, error)
+	SaveSession(ctx context.Context, session Session) error
+	GetSessionHistory(ctx context.Context, id string, sinceRecordID int64) ([]Record, error)
+
+	SaveIssue(ctx context.Context, issue Issue) (int64, error)
+	GetIssue(ctx context.Context, id int64) (*Issue, error)
+	GetTaskIssues(ctx context.Context, taskID string) ([]Issue, error)
+	GetOpenIssues(ctx context.Context, team string) ([]Issue, error)
+	AnswerIssue(ctx context.Context, id int64, answer, answeredBy string) error
 }
 
 type Record struct {
@@ -23,6 +89,108 @@ type Record struct {
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
 
+type QueuedTask struct {
+	TaskID      string `json:"task_id" db:"task_id"`
+	Team        string `json:"team" db:"team"`
+	Description string `json:"description" db:"description"`
+	Origin      string `json:"origin" db:"origin"`
+	Channel     string `json:"channel" db:"channel"`
+	Priority    int    `json:"priority" db:"priority"`
+	Budget      string `json:"budget" db:"budget"`
+	Status      string `json:"status" db:"status"`
+	// ParentID is the task that delegated this one to a sub-team, sub-tasks
+	// are never claimed from the queue, their parent runs them.
+	ParentID string `json:"parent_id" db:"parent_id"`
+	// Error is why the task did not complete, with the stack trace when it panicked.
+	Error      string    `json:"error" db:"error"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	StartedAt  time.Time `json:"started_at" db:"started_at"`
+	FinishedAt time.Time `json:"finished_at" db:"finished_at"`
+}
+
+func (t QueuedTask) IsFinished() bool {
+	return t.Status == TaskCompleted || t.Status == TaskFailed || t.Status == TaskCancelled ||
+		t.Status == TaskBudgetOut
+}
+
+// Checkpoint is the execution state of a task saved after every iteration,
+// it allows an interrupted task to continue without planning again.
+type Checkpoint struct {
+	TaskID            string    `json:"task_id" db:"task_id"`
+	Plan              string    `json:"plan" db:"plan"`
+	Step              int       `json:"step" db:"step"`
+	SummarizedRecords int       `json:"summarized_records" db:"summarized_records"`
+	Summary           string    `json:"summary" db:"summary"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ScheduleRun is the last run of a schedule, TaskStatus is read from the queue
+// and is empty when the run didn't queue a task.
+type ScheduleRun struct {
+	Name       string    `json:"name" db:"name"`
+	Team       string    `json:"team" db:"team"`
+	RunAt      time.Time `json:"run_at" db:"run_at"`
+	TaskID     string    `json:"task_id" db:"task_id"`
+	Result     string    `json:"result" db:"result"`
+	Error      string    `json:"error" db:"error"`
+	TaskStatus string    `json:"task_status" db:"-"`
+}
+
+// TaskResult is the outcome of a task, saved when it stops running.
+type TaskResult struct {
+	TaskID      string `json:"task_id" db:"task_id"`
+	Team        string `json:"team" db:"team"`
+	Description string `json:"description" db:"description"`
+	Status      string `json:"status" db:"status"`
+	// Answer is the output of the last step, Reason why the task was considered finished.
+	Answer   string        `json:"answer" db:"answer"`
+	Reason   string        `json:"reason" db:"reason"`
+	Steps    int           `json:"steps" db:"steps"`
+	Tokens   int64         `json:"tokens" db:"tokens"`
+	Duration time.Duration `json:"duration" db:"duration_ms"`
+	// Files are the changes found in the workspace while the task ran.
+	Files      []FileChange `json:"files" db:"files"`
+	Error      string       `json:"error" db:"error"`
+	StartedAt  time.Time    `json:"started_at" db:"started_at"`
+	FinishedAt time.Time    `json:"finished_at" db:"finished_at"`
+}
+
+type FileChange struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+}
+
+// Issue is a problem reported by a worker with report_issue, it stays open
+// until a human answers it.
+type Issue struct {
+	ID         int64     `json:"id" db:"id"`
+	TaskID     string    `json:"task_id" db:"task_id"`
+	Team       string    `json:"team" db:"team"`
+	StepID     int       `json:"step_id" db:"step_id"`
+	MemberID   string    `json:"member_id" db:"member_id"`
+	Severity   string    `json:"severity" db:"severity"`
+	Reason     string    `json:"reason" db:"reason"`
+	Status     string    `json:"status" db:"status"`
+	Answer     string    `json:"answer" db:"answer"`
+	AnsweredBy string    `json:"answered_by" db:"answered_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	AnsweredAt time.Time `json:"answered_at" db:"answered_at"`
+}
+
+// Session is the conversation of a user on a channel of a client, outside of
+// any task. Its messages are records saved under the session ID.
+type Session struct {
+	ID      string `json:"id" db:"id"`
+	Origin  string `json:"origin" db:"origin"`
+	Channel string `json:"channel" db:"channel"`
+	UserID  string `json:"user_id" db:"user_id"`
+	// Summary condenses the messages up to SinceRecordID.
+	Summary string `json:"summary" db:"summary"`
+	// SinceRecordID hides the records up to it, they are in the summary or were reset.
+	SinceRecordID int64     `json:"since_record_id" db:"since_record_id"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
 func RecordListToString(records []Record, countSteps int) string {
 	recordsSliced := records
 	var historySummary string
@@ -31,11 +199,16 @@ func RecordListToString(records []Record, countSteps int) string {
 			recordsSliced = records[:countSteps]
 		}
 		for _, entry := range recordsSliced {
-			if entry.Role == "tool" || entry.Role == "assistant" {
-				historySummary += fmt.Sprintf("\nRole: %s | Tool: %s |  Content: %s",
-					entry.Role, entry.Tool, entry.Content)
-			}
+			historySummary += FormatRecord(entry)
 		}
 	}
 	return historySummary
 }
+
+// FormatRecord renders a tool or assistant record as a history line, other roles render empty.
+func FormatRecord(record Record) string {
+	if record.Role != "tool" && record.Role != "assistant" {
+		return ""
+	}
+	return fmt.Sprintf("\nRole: %s | Tool: %s |  Content: %s", record.Role, record.Tool, record.Content)
+}
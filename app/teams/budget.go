@@ -0,0 +1,46 @@
+package teams
+
+import "time"
+
+const defaultMaxConsecutiveFailures = 5
+
+// Budget limits how much a task can consume before the runtime stops it,
+// zero values mean no limit.
+type Budget struct {
+	MaxSteps               int           `yaml:"max_steps,omitempty" json:"max_steps,omitempty"`
+	MaxConsecutiveFailures int           `yaml:"max_consecutive_failures,omitempty" json:"max_consecutive_failures,omitempty"`
+	MaxTokens              int           `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty"`
+	MaxDuration            time.Duration `yaml:"max_duration,omitempty" json:"max_duration,omitempty"`
+}
+
+// Merge returns the budget with the non-zero limits of override applied on top.
+func (b Budget) Merge(override *Budget) Budget {
+	if override == nil {
+		return b
+	}
+	if override.MaxSteps > 0 {
+		b.MaxSteps = override.MaxSteps
+	}
+	if override.MaxConsecutiveFailures > 0 {
+		b.MaxConsecutiveFailures = override.MaxConsecutiveFailures
+	}
+	if override.MaxTokens > 0 {
+		b.MaxTokens = override.MaxTokens
+	}
+	if override.MaxDuration > 0 {
+		b.MaxDuration = override.MaxDuration
+	}
+	return b
+}
+
+// WithDefaults caps consecutive failures so a broken model endpoint can't loop forever.
+func (b Budget) WithDefaults() Budget {
+	if b.MaxConsecutiveFailures <= 0 {
+		b.MaxConsecutiveFailures = defaultMaxConsecutiveFailures
+	}
+	return b
+}
+
+func (b Budget) IsZero() bool {
+	return b == Budget{}
+}
@@ -0,0 +1,97 @@
+package teams
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Completion policies, the leader model judges the summary when none is set.
+const (
+	CompletionJudge    = "llm_judge"
+	CompletionReviewer = "reviewer"
+	CompletionCommand  = "command"
+	CompletionFiles    = "files"
+	CompletionAll      = "all"
+	CompletionAny      = "any"
+
+	defaultCompletionTimeout = 5 * time.Minute
+)
+
+// Completion decides when the leader has finished the task, it is checked
+// after every step. All and any combine the nested policies.
+type Completion struct {
+	Type string `yaml:"type"`
+	// Command runs in the workspace, the task is complete when it exits 0.
+	Command string        `yaml:"command,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// Files are glob patterns relative to the workspace, each one must match a file.
+	Files []string `yaml:"files,omitempty"`
+	// Match is a regular expression the content of every matched file must contain.
+	Match    string       `yaml:"match,omitempty"`
+	Policies []Completion `yaml:"policies,omitempty"`
+}
+
+func (c Completion) CommandTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return defaultCompletionTimeout
+	}
+	return c.Timeout
+}
+
+// NeedsWorkspace reports whether the policy, or a nested one, checks the workspace.
+func (c Completion) NeedsWorkspace() bool {
+	if c.Type == CompletionCommand || c.Type == CompletionFiles {
+		return true
+	}
+	for _, policy := range c.Policies {
+		if policy.NeedsWorkspace() {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Completion) Validate(members []string) error {
+	switch c.Type {
+	case CompletionJudge:
+	case CompletionReviewer:
+		if !slices.Contains(members, reviewerKey) {
+			return fmt.Errorf("completion %s needs a '%s' member", c.Type, reviewerKey)
+		}
+	case CompletionCommand:
+		if c.Command == "" {
+			return fmt.Errorf("completion %s: command cannot be empty", c.Type)
+		}
+		if c.Timeout < 0 {
+			return fmt.Errorf("completion %s: timeout cannot be negative", c.Type)
+		}
+	case CompletionFiles:
+		if len(c.Files) == 0 {
+			return fmt.Errorf("completion %s: files cannot be empty", c.Type)
+		}
+		for _, pattern := range c.Files {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("completion %s: pattern %q: %w", c.Type, pattern, err)
+			}
+		}
+		if _, err := regexp.Compile(c.Match); err != nil {
+			return fmt.Errorf("completion %s: match: %w", c.Type, err)
+		}
+	case CompletionAll, CompletionAny:
+		if len(c.Policies) == 0 {
+			return fmt.Errorf("completion %s: policies cannot be empty", c.Type)
+		}
+		for _, policy := range c.Policies {
+			if err := policy.Validate(members); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown completion %q, expected %s, %s, %s, %s, %s or %s", c.Type, CompletionJudge,
+			CompletionReviewer, CompletionCommand, CompletionFiles, CompletionAll, CompletionAny)
+	}
+	return nil
+}
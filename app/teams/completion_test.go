@@ -0,0 +1,38 @@
+package teams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionValidate(t *testing.T) {
+	members := []string{"leader", "coder"}
+
+	assert.NoError(t, Completion{Type: CompletionAll, Policies: []Completion{
+		{Type: CompletionCommand, Command: "go test ./..."},
+		{Type: CompletionAny, Policies: []Completion{
+			{Type: CompletionFiles, Files: []string{"*.go"}, Match: `func Test`},
+			{Type: CompletionJudge},
+		}},
+	}}.Validate(members))
+
+	assert.Error(t, Completion{Type: "tests"}.Validate(members))
+	assert.Error(t, Completion{Type: CompletionReviewer}.Validate(members))
+	assert.NoError(t, Completion{Type: CompletionReviewer}.Validate(append(members, "reviewer")))
+	assert.Error(t, Completion{Type: CompletionCommand}.Validate(members))
+	assert.Error(t, Completion{Type: CompletionFiles, Files: []string{"[a"}}.Validate(members))
+	assert.Error(t, Completion{Type: CompletionFiles, Files: []string{"*.go"}, Match: "("}.Validate(members))
+	assert.Error(t, Completion{Type: CompletionAny}.Validate(members))
+	assert.Error(t, Completion{Type: CompletionAll, Policies: []Completion{{Type: CompletionCommand}}}.Validate(members))
+}
+
+func TestCompletionNeedsWorkspace(t *testing.T) {
+	assert.False(t, Completion{Type: CompletionJudge}.NeedsWorkspace())
+	assert.True(t, Completion{Type: CompletionFiles}.NeedsWorkspace())
+	assert.True(t, Completion{Type: CompletionAny, Policies: []Completion{
+		{Type: CompletionReviewer},
+		{Type: CompletionAll, Policies: []Completion{{Type: CompletionCommand}}},
+	}}.NeedsWorkspace())
+	assert.False(t, Completion{Type: CompletionAll, Policies: []Completion{{Type: CompletionReviewer}}}.NeedsWorkspace())
+}
@@ -0,0 +1,181 @@
+package teams
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	StepPending    = "pending"
+	StepInProgress = "in_progress"
+	StepDone       = "done"
+	StepFailed     = "failed"
+	StepSkipped    = "skipped"
+)
+
+var planStepPattern = regexp.MustCompile(`^\s*(\d+)\s*[.)-]\s*(.+?)\s*$`)
+
+type PlanStep struct {
+	Number      int    `json:"number"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Failures    int    `json:"failures,omitempty"`
+}
+
+type Plan struct {
+	Steps    []PlanStep `json:"steps"`
+	Revision int        `json:"revision,omitempty"`
+}
+
+// ParsePlan reads the numbered list produced by the planner. Lines that are not
+// numbered are appended to the previous step, and when no line is numbered
+// every non-empty line becomes a step.
+func ParsePlan(text string) Plan {
+	var plan Plan
+	var loose []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if match := planStepPattern.FindStringSubmatch(line); match != nil {
+			plan.addStep(strings.Trim(match[2], "[]"))
+			continue
+		}
+		loose = append(loose, strings.TrimLeft(line, "-*• "))
+		if len(plan.Steps) > 0 {
+			last := &plan.Steps[len(plan.Steps)-1]
+			last.Description += " " + line
+		}
+	}
+
+	if len(plan.Steps) == 0 {
+		for _, line := range loose {
+			plan.addStep(line)
+		}
+	}
+	return plan
+}
+
+// DecodePlan reads a plan stored as JSON, falling back to the planner text format.
+func DecodePlan(raw string) Plan {
+	var plan Plan
+	if err := json.Unmarshal([]byte(raw), &plan); err == nil && len(plan.Steps) > 0 {
+		return plan
+	}
+	return ParsePlan(raw)
+}
+
+func (p Plan) Encode() string {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return p.String()
+	}
+	return string(raw)
+}
+
+func (p *Plan) addStep(description string) {
+	p.Steps = append(p.Steps, PlanStep{
+		Number:      len(p.Steps) + 1,
+		Description: description,
+		Status:      StepPending,
+	})
+}
+
+func (p *Plan) Step(number int) *PlanStep {
+	for i := range p.Steps {
+		if p.Steps[i].Number == number {
+			return &p.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Current returns the first step that is not finished yet.
+func (p *Plan) Current() *PlanStep {
+	for i := range p.Steps {
+		if p.Steps[i].Status != StepDone && p.Steps[i].Status != StepSkipped {
+			return &p.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Resolve returns the step a delegation works on, the current one when the
+// leader didn't name a valid step.
+func (p *Plan) Resolve(number int) *PlanStep {
+	if step := p.Step(number); step != nil {
+		return step
+	}
+	return p.Current()
+}
+
+func (p *Plan) SetStatus(number int, status string) {
+	step := p.Step(number)
+	if step == nil {
+		return
+	}
+	step.Status = status
+	if status == StepFailed {
+		step.Failures++
+	}
+}
+
+func (p Plan) Finished() bool {
+	for _, step := range p.Steps {
+		if step.Status != StepDone && step.Status != StepSkipped {
+			return false
+		}
+	}
+	return true
+}
+
+// Progress returns the position of the current step and the number of steps
+// that still count, skipped steps are left out.
+func (p Plan) Progress() (int, int) {
+	var done, total int
+	for _, step := range p.Steps {
+		switch step.Status {
+		case StepSkipped:
+			continue
+		case StepDone:
+			done++
+		}
+		total++
+	}
+	return min(done+1, total), total
+}
+
+func (p Plan) ProgressText() string {
+	current, total := p.Progress()
+	if total == 0 {
+		return "no plan yet"
+	}
+	if p.Finished() {
+		return fmt.Sprintf("all %d steps done", total)
+	}
+	return fmt.Sprintf("step %d of %d", current, total)
+}
+
+// Revise keeps the finished steps, skips the remaining ones and appends the new steps after them.
+func (p *Plan) Revise(next Plan) {
+	for i := range p.Steps {
+		if p.Steps[i].Status != StepDone {
+			p.Steps[i].Status = StepSkipped
+		}
+	}
+	for _, step := range next.Steps {
+		p.addStep(step.Description)
+	}
+	p.Revision++
+}
+
+func (p Plan) String() string {
+	lines := make([]string, 0, len(p.Steps))
+	for _, step := range p.Steps {
+		lines = append(lines, fmt.Sprintf("%d. [%s] %s", step.Number, step.Status, step.Description))
+	}
+	return strings.Join(lines, "\n")
+}
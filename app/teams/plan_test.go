@@ -0,0 +1,54 @@
+package teams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePlan(t *testing.T) {
+	cases := []struct {
+		name  string
+		text  string
+		steps []string
+	}{
+		{"numbered", "1. Create main.go\n2. Add handler\n3. Write tests", []string{"Create main.go", "Add handler", "Write tests"}},
+		{"brackets", "1. [Create main.go]\n2) [Add handler]", []string{"Create main.go", "Add handler"}},
+		{"continuation", "1. Create main.go\n   with a gin router\n2. Add handler", []string{"Create main.go with a gin router", "Add handler"}},
+		{"not_numbered", "- Create main.go\n- Add handler", []string{"Create main.go", "Add handler"}},
+		{"empty", "", nil},
+	}
+	for _, cse := range cases {
+		t.Run(cse.name, func(t *testing.T) {
+			plan := ParsePlan(cse.text)
+			var steps []string
+			for i, step := range plan.Steps {
+				assert.Equal(t, i+1, step.Number)
+				assert.Equal(t, StepPending, step.Status)
+				steps = append(steps, step.Description)
+			}
+			assert.Equal(t, cse.steps, steps)
+		})
+	}
+}
+
+func TestPlanProgress(t *testing.T) {
+	plan := ParsePlan("1. a\n2. b\n3. c")
+	assert.Equal(t, "step 1 of 3", plan.ProgressText())
+
+	plan.SetStatus(1, StepDone)
+	plan.SetStatus(2, StepFailed)
+	assert.Equal(t, "step 2 of 3", plan.ProgressText())
+	assert.Equal(t, 1, plan.Step(2).Failures)
+	assert.Equal(t, 2, plan.Current().Number)
+
+	plan.Revise(ParsePlan("1. b2\n2. c2"))
+	assert.Equal(t, StepDone, plan.Step(1).Status)
+	assert.Equal(t, StepSkipped, plan.Step(2).Status)
+	assert.Equal(t, StepSkipped, plan.Step(3).Status)
+	assert.Equal(t, "b2", plan.Step(4).Description)
+	assert.Equal(t, "step 2 of 3", plan.ProgressText())
+
+	decoded := DecodePlan(plan.Encode())
+	assert.Equal(t, plan, decoded)
+}
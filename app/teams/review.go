@@ -0,0 +1,47 @@
+package teams
+
+const (
+	reviewerKey            = "reviewer"
+	defaultMaxReviewRounds = 2
+)
+
+// ReviewPolicy makes the reviewer member check the output of the workers,
+// a rejected output goes back to the same worker for a bounded number of rounds.
+type ReviewPolicy struct {
+	Enabled   bool     `yaml:"enabled"`
+	MaxRounds int      `yaml:"max_rounds,omitempty"`
+	Workers   []string `yaml:"workers,omitempty"`
+	Steps     []int    `yaml:"steps,omitempty"`
+}
+
+func (p ReviewPolicy) Rounds() int {
+	if p.MaxRounds <= 0 {
+		return defaultMaxReviewRounds
+	}
+	return p.MaxRounds
+}
+
+// Applies reports whether the output of the worker on the given plan step must
+// be reviewed, empty lists match every worker and every step.
+func (p ReviewPolicy) Applies(worker string, step int) bool {
+	if !p.Enabled || worker == reviewerKey {
+		return false
+	}
+	return matches(p.Workers, worker) && matches(p.Steps, step)
+}
+
+func matches[T comparable](list []T, value T) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Team) GetReviewer() *Member {
+	return t.Members[reviewerKey]
+}
@@ -2,10 +2,12 @@ package teams
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/google/uuid"
 
+	"GoWorkerAI/app/models"
 	"GoWorkerAI/app/utils"
 )
 
@@ -15,9 +17,16 @@ const (
 )
 
 type Team struct {
+	Name    string
 	Members map[string]*Member
 	Task    *Task
-	Audits  *utils.AuditLogger
+	Budget  Budget
+	Review  ReviewPolicy
+	// Workflow replaces the leader delegation with fixed steps when set.
+	Workflow *Workflow
+	// Completion decides when the leader has finished, the leader model judges it when nil.
+	Completion *Completion
+	Audits     *utils.AuditLogger
 }
 
 func (t *Team) GetLeader() *Member {
@@ -32,6 +41,30 @@ func (t *Team) GetMember(key string) *Member {
 	return t.Members[key]
 }
 
+// Clone returns a copy of the team bound to the given task, so several tasks
+// can run at once without sharing member state.
+func (t *Team) Clone(task *Task) *Team {
+	members := make(map[string]*Member, len(t.Members))
+	for key, m := range t.Members {
+		member := *m
+		member.Task = task
+		if m.Interface != nil {
+			member.Interface = m.Interface.Clone()
+		}
+		members[key] = &member
+	}
+	return &Team{
+		Name:       t.Name,
+		Members:    members,
+		Task:       task,
+		Budget:     t.Budget,
+		Review:     t.Review,
+		Workflow:   t.Workflow,
+		Completion: t.Completion,
+		Audits:     t.Audits,
+	}
+}
+
 func (t *Team) Close() error {
 	// Close the audit logger but DO NOT clear the logs
 	// Logs must persist for debugging and task resumption
@@ -45,9 +78,16 @@ func (t *Team) GetMembersOptions() []string {
 	if t == nil {
 		return nil
 	}
+	// Sorted so the prompt is the same on every run, a recorded cassette must replay it.
+	keys := make([]string, 0, len(t.Members))
+	for key := range t.Members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 	options := make([]string, 0, len(t.Members))
-	for _, member := range t.Members {
-		if member.Key == leaderKey || member.Key == eventHandlerKey {
+	for _, key := range keys {
+		member := t.Members[key]
+		if !t.delegable(member) {
 			continue
 		}
 
@@ -63,20 +103,65 @@ func (t *Team) GetMembersOptions() []string {
 			whenToUseSection,
 			toolsList,
 		)
+		if member.SubTeam != nil {
+			memberOption = fmt.Sprintf("WORKER_NAME: \"%s\"%s\n  TEAM: %s, it plans and completes whole objectives with its workers",
+				member.Key,
+				whenToUseSection,
+				member.SubTeam.Name,
+			)
+		}
 
 		options = append(options, memberOption)
 	}
 	return options
 }
 
+// DelegateWorkers returns the sorted keys of the members the leader can delegate to.
+func (t *Team) DelegateWorkers() []string {
+	workers := make([]string, 0, len(t.Members))
+	for key, member := range t.Members {
+		if t.delegable(member) {
+			workers = append(workers, key)
+		}
+	}
+	sort.Strings(workers)
+	return workers
+}
+
+func (t *Team) delegable(member *Member) bool {
+	return member.Key != leaderKey && member.Key != eventHandlerKey &&
+		(member.Key != reviewerKey || !t.Review.Enabled)
+}
+
 type Member struct {
-	Key          string
-	SystemPrompt string
-	WhenCall     string
-	Task         *Task
+	Key              string
+	SystemPrompt     string
+	WhenCall         string
+	RequiresApproval []string
+	// MaxToolRounds caps the rounds of tool calls the member makes on a single step.
+	MaxToolRounds int
+	// Settings are the model, endpoint and sampling of the member, merged over the
+	// ones of its team. Model is the client the runtime made for them.
+	Settings models.Settings
+	Model    models.Interface
+	// SubTeam makes the member delegate its subtasks to a whole team, which
+	// plans and runs them with its own leader and workers.
+	SubTeam *Team
+	Task    *Task
 	Interface
 }
 
+// NeedsApproval reports whether a call to the tool must be approved by a human first,
+// "*" requires approval for every tool of the member.
+func (m *Member) NeedsApproval(toolName string) bool {
+	for _, name := range m.RequiresApproval {
+		if name == "*" || name == toolName {
+			return true
+		}
+	}
+	return false
+}
+
 func NewMember(key, systemPrompt, whenCall string, worker Interface) *Member {
 	return &Member{
 		Key:          key,
@@ -89,6 +174,20 @@ func NewMember(key, systemPrompt, whenCall string, worker Interface) *Member {
 type Task struct {
 	ID          uuid.UUID
 	Description string
+	Origin      string
+	Channel     string
+	Priority    int
+	Budget      *Budget
+	// Parent is the task that delegated this one to a sub-team.
+	Parent *Task
+}
+
+// Root returns the top level task, the one that was queued.
+func (t *Task) Root() *Task {
+	for t.Parent != nil {
+		t = t.Parent
+	}
+	return t
 }
 
 func (m *Member) SetTask(task *Task) {
@@ -115,12 +214,13 @@ func (t Team) GetTeamMemberByKey(key string) *Member {
 	return m
 }
 
-func NewTeam(members []*Member, task string) *Team {
+func NewTeam(name string, members []*Member, task string) *Team {
 	memberMap := make(map[string]*Member)
 	for _, member := range members {
 		memberMap[member.Key] = member
 	}
 	return &Team{
+		Name:    name,
 		Members: memberMap,
 		Task: &Task{
 			ID:          uuid.New(),
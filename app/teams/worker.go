@@ -2,6 +2,7 @@ package teams
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"GoWorkerAI/app/tools"
@@ -14,6 +15,7 @@ type Interface interface {
 	AddTools(tool []tools.Tool)
 	SetToolKit(tk map[string]tools.Tool)
 	GetToolKit() map[string]tools.Tool
+	Clone() Interface
 }
 
 type Worker struct {
@@ -23,6 +25,18 @@ type Worker struct {
 	Toolkit     map[string]tools.Tool
 }
 
+func (w *Worker) Clone() Interface {
+	if w == nil {
+		return nil
+	}
+	clone := *w
+	clone.Toolkit = make(map[string]tools.Tool, len(w.Toolkit))
+	for name, tool := range w.Toolkit {
+		clone.Toolkit[name] = tool
+	}
+	return &clone
+}
+
 func (w *Worker) SetToolKit(tk map[string]tools.Tool) {
 	if w != nil {
 		w.Toolkit = tk
@@ -40,8 +54,14 @@ func (w *Worker) GetToolsOptions() []string {
 	if w == nil {
 		return nil
 	}
+	names := make([]string, 0, len(w.Toolkit))
+	for name := range w.Toolkit {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 	options := make([]string, 0, len(w.Toolkit))
-	for _, tool := range w.Toolkit {
+	for _, name := range names {
+		tool := w.Toolkit[name]
 		options = append(options, fmt.Sprintf("tool name: %s { description: %s } ", tool.Name, tool.Description))
 	}
 	return options
@@ -0,0 +1,164 @@
+package teams
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+var workflowFuncs = template.FuncMap{
+	"contains":  strings.Contains,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"trim":      strings.TrimSpace,
+}
+
+// Workflow is a fixed sequence of steps the runtime executes instead of letting
+// the leader delegate, steps can be skipped by a condition or repeated by a loop.
+type Workflow struct {
+	Steps []WorkflowStep `yaml:"steps"`
+	// FallbackToLeader hands the task to the leader when a step fails.
+	FallbackToLeader bool `yaml:"fallback_to_leader,omitempty"`
+}
+
+// WorkflowStep runs the instruction template on a member. Instruction, When and
+// Loop.While are Go templates over WorkflowVars, e.g. {{.Outputs.design}}.
+type WorkflowStep struct {
+	Name        string `yaml:"name"`
+	Member      string `yaml:"member"`
+	Instruction string `yaml:"instruction"`
+	// Inputs are the previous steps whose outputs are given to the member as context.
+	Inputs []string `yaml:"inputs,omitempty"`
+	// When skips the step unless it renders "true".
+	When string        `yaml:"when,omitempty"`
+	Loop *WorkflowLoop `yaml:"loop,omitempty"`
+}
+
+// WorkflowLoop goes back to the step To, the current one by default, while the
+// condition renders "true" and the step ran less than MaxIterations times.
+type WorkflowLoop struct {
+	To            string `yaml:"to,omitempty"`
+	While         string `yaml:"while"`
+	MaxIterations int    `yaml:"max_iterations"`
+}
+
+type WorkflowVars struct {
+	Task    string
+	Outputs map[string]string
+	// Output is the output of the current step, set when evaluating Loop.While.
+	Output    string
+	Iteration int
+}
+
+func (w *Workflow) Validate(members []string) error {
+	if len(w.Steps) == 0 {
+		return fmt.Errorf("workflow has no steps")
+	}
+	seen := make(map[string]bool, len(w.Steps))
+	for _, step := range w.Steps {
+		switch {
+		case step.Name == "":
+			return fmt.Errorf("workflow step name cannot be empty")
+		case seen[step.Name]:
+			return fmt.Errorf("duplicated workflow step %s", step.Name)
+		case !slices.Contains(members, step.Member):
+			return fmt.Errorf("workflow step %s: member %s not found", step.Name, step.Member)
+		case step.Instruction == "":
+			return fmt.Errorf("workflow step %s: instruction cannot be empty", step.Name)
+		}
+		for _, input := range step.Inputs {
+			if !seen[input] {
+				return fmt.Errorf("workflow step %s: input %s must be a previous step", step.Name, input)
+			}
+		}
+		seen[step.Name] = true
+
+		for _, text := range []string{step.Instruction, step.When} {
+			if _, err := parseWorkflowTemplate(step.Name, text); err != nil {
+				return fmt.Errorf("workflow step %s: %w", step.Name, err)
+			}
+		}
+		if loop := step.Loop; loop != nil {
+			if loop.To != "" && !seen[loop.To] {
+				return fmt.Errorf("workflow step %s: loop must go back to this or a previous step, got %s",
+					step.Name, loop.To)
+			}
+			if loop.While == "" || loop.MaxIterations <= 0 {
+				return fmt.Errorf("workflow step %s: loop needs a while condition and max_iterations", step.Name)
+			}
+			if _, err := parseWorkflowTemplate(step.Name, loop.While); err != nil {
+				return fmt.Errorf("workflow step %s: %w", step.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Index returns the position of the named step, -1 when it doesn't exist.
+func (w *Workflow) Index(name string) int {
+	for i, step := range w.Steps {
+		if step.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s WorkflowStep) Render(vars WorkflowVars) (string, error) {
+	return renderWorkflowTemplate(s.Name, s.Instruction, vars)
+}
+
+func (s WorkflowStep) ShouldRun(vars WorkflowVars) (bool, error) {
+	if s.When == "" {
+		return true, nil
+	}
+	return evalWorkflowCondition(s.Name, s.When, vars)
+}
+
+// Again reports whether the loop goes back, ran is the number of times the step was executed.
+func (l WorkflowLoop) Again(stepName string, vars WorkflowVars, ran int) (bool, error) {
+	if ran >= l.MaxIterations {
+		return false, nil
+	}
+	return evalWorkflowCondition(stepName, l.While, vars)
+}
+
+// Context joins the outputs of the step inputs to give them to the member.
+func (s WorkflowStep) Context(outputs map[string]string) string {
+	parts := make([]string, 0, len(s.Inputs))
+	for _, input := range s.Inputs {
+		parts = append(parts, fmt.Sprintf("Output of %s:\n%s", input, outputs[input]))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func parseWorkflowTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(workflowFuncs).Option("missingkey=zero").Parse(text)
+}
+
+func renderWorkflowTemplate(name, text string, vars WorkflowVars) (string, error) {
+	tmpl, err := parseWorkflowTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err = tmpl.Execute(&sb, vars); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func evalWorkflowCondition(name, text string, vars WorkflowVars) (bool, error) {
+	result, err := renderWorkflowTemplate(name, text, vars)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(result)) {
+	case "true", "yes", "1":
+		return true, nil
+	}
+	return false, nil
+}
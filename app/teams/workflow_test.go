@@ -0,0 +1,60 @@
+package teams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowValidate(t *testing.T) {
+	members := []string{"leader", "coder", "reviewer"}
+	valid := Workflow{Steps: []WorkflowStep{
+		{Name: "implement", Member: "coder", Instruction: "{{.Task}}"},
+		{Name: "review", Member: "reviewer", Instruction: "Review: {{.Outputs.implement}}", Inputs: []string{"implement"},
+			Loop: &WorkflowLoop{To: "implement", While: `{{contains .Output "REJECTED"}}`, MaxIterations: 3}},
+	}}
+	assert.NoError(t, valid.Validate(members))
+
+	cases := map[string]Workflow{
+		"empty":          {},
+		"unknown_member": {Steps: []WorkflowStep{{Name: "a", Member: "tester", Instruction: "x"}}},
+		"later_input":    {Steps: []WorkflowStep{{Name: "a", Member: "coder", Instruction: "x", Inputs: []string{"b"}}}},
+		"forward_loop": {Steps: []WorkflowStep{{Name: "a", Member: "coder", Instruction: "x",
+			Loop: &WorkflowLoop{To: "b", While: "true", MaxIterations: 2}}, {Name: "b", Member: "coder", Instruction: "y"}}},
+		"no_max_iterations": {Steps: []WorkflowStep{{Name: "a", Member: "coder", Instruction: "x",
+			Loop: &WorkflowLoop{While: "true"}}}},
+		"bad_template": {Steps: []WorkflowStep{{Name: "a", Member: "coder", Instruction: "{{.Task"}}},
+	}
+	for name, workflow := range cases {
+		assert.Error(t, workflow.Validate(members), name)
+	}
+}
+
+func TestWorkflowStepTemplates(t *testing.T) {
+	step := WorkflowStep{
+		Name:        "fix",
+		Instruction: "Fix {{.Task}} using {{.Outputs.review}}",
+		Inputs:      []string{"review"},
+		When:        `{{contains .Outputs.review "REJECTED"}}`,
+		Loop:        &WorkflowLoop{While: `{{not (hasPrefix .Output "OK")}}`, MaxIterations: 2},
+	}
+	vars := WorkflowVars{Task: "the API", Outputs: map[string]string{"review": "REJECTED: no tests"}}
+
+	text, err := step.Render(vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "Fix the API using REJECTED: no tests", text)
+	assert.Equal(t, "Output of review:\nREJECTED: no tests", step.Context(vars.Outputs))
+
+	run, err := step.ShouldRun(vars)
+	assert.NoError(t, err)
+	assert.True(t, run)
+
+	vars.Output = "still failing"
+	again, _ := step.Loop.Again(step.Name, vars, 1)
+	assert.True(t, again)
+	again, _ = step.Loop.Again(step.Name, vars, 2)
+	assert.False(t, again)
+	vars.Output = "OK"
+	again, _ = step.Loop.Again(step.Name, vars, 1)
+	assert.False(t, again)
+}
@@ -0,0 +1,80 @@
+package tools
+
+import "slices"
+
+// NoWorker is the worker the leader names to skip a subtask or to finish the task.
+const NoWorker = "none"
+
+// NewDelegateToolkit returns the delegate tools with the worker restricted to
+// the given member keys and NoWorker.
+func NewDelegateToolkit(workers []string) map[string]Tool {
+	return map[string]Tool{
+		delegate_task:  delegateTaskTool(workers),
+		delegate_tasks: delegateTasksTool(workers),
+	}
+}
+
+func delegateTaskTool(workers []string) Tool {
+	return Tool{
+		Name:        delegate_task,
+		Description: "Assign a clear, atomic task required to complete the main task to a worker from the team.",
+		Parameters: Parameter{
+			Type:       "object",
+			Properties: delegateProperties(workers),
+			Required:   []string{"worker", "task"},
+		},
+	}
+}
+
+func delegateTasksTool(workers []string) Tool {
+	return Tool{
+		Name: delegate_tasks,
+		Description: "Assign several independent, atomic tasks to different workers of the team at once. " +
+			"They run in parallel, so only batch tasks that do not depend on each other's results.",
+		Parameters: Parameter{
+			Type: "object",
+			Properties: map[string]any{
+				"tasks": map[string]any{
+					"type":        "array",
+					"description": "The independent tasks to run in parallel, one entry per delegation.",
+					"minItems":    1,
+					"items": map[string]any{
+						"type":       "object",
+						"properties": delegateProperties(workers),
+						"required":   []string{"worker", "task"},
+					},
+				},
+			},
+			Required: []string{"tasks"},
+		},
+	}
+}
+
+// delegateProperties describes a single delegation, the worker is free text
+// when no workers are given.
+func delegateProperties(workers []string) map[string]any {
+	worker := map[string]any{
+		"type":        "string",
+		"description": "The worker to delegate the task to, \"" + NoWorker + "\" to skip it or finish the task.",
+	}
+	if len(workers) > 0 {
+		worker["enum"] = append(slices.Clone(workers), NoWorker)
+	}
+	return map[string]any{
+		"worker": worker,
+		"step": map[string]any{
+			"type":        "integer",
+			"description": "The number of the plan step this task works on.",
+		},
+		"task": map[string]any{
+			"type":        "string",
+			"description": "A single, focused goal describing the exact action or deliverable expected.",
+			"maxLength":   100,
+		},
+		"context": map[string]any{
+			"type":        "string",
+			"description": "Optional brief context or background needed for the worker to execute the task effectively (avoid redundancy).",
+			"maxLength":   500,
+		},
+	}
+}
@@ -0,0 +1,104 @@
+package tools
+
+// Tools of the event handler, the runtime binds their handlers to the task queue.
+const (
+	CreateTaskTool     = "create_task"
+	CancelTaskTool     = "cancel_task"
+	GetTaskStatusTool  = "get_task_status"
+	SearchHistoryTool  = "search_history"
+	InjectIntoTaskTool = "inject_into_task"
+)
+
+type CreateTaskAction struct {
+	Description string `json:"description"`
+	Team        string `json:"team"`
+	Priority    int    `json:"priority"`
+}
+
+type TaskAction struct {
+	TaskID string `json:"task_id"`
+}
+
+type SearchHistoryAction struct {
+	Query  string `json:"query"`
+	TaskID string `json:"task_id"`
+}
+
+type InjectAction struct {
+	TaskID  string `json:"task_id"`
+	Message string `json:"message"`
+}
+
+var eventHandlerTools = map[string]Tool{
+	CreateTaskTool: {
+		Name:        CreateTaskTool,
+		Description: "Queue a new task for a team when the user asks for work to be done.",
+		Parameters: Parameter{
+			Type: "object",
+			Properties: map[string]any{
+				"description": map[string]any{
+					"type":        "string",
+					"description": "The complete task, with every detail the user gave.",
+				},
+				"team": map[string]any{
+					"type":        "string",
+					"description": "The team that runs the task, empty for the default team.",
+				},
+				"priority": map[string]any{
+					"type":        "integer",
+					"description": "Higher priorities run first, 0 by default.",
+				},
+			},
+			Required: []string{"description"},
+		},
+	},
+	CancelTaskTool: {
+		Name:        CancelTaskTool,
+		Description: "Cancel a running task or drop a pending one from the queue.",
+		Parameters: Parameter{
+			Type: "object",
+			Properties: map[string]any{
+				"task_id": map[string]any{"type": "string"},
+			},
+			Required: []string{"task_id"},
+		},
+	},
+	GetTaskStatusTool: {
+		Name:        GetTaskStatusTool,
+		Description: "Get the status or the result of a task, or the queue of every team when no task is given.",
+		Parameters: Parameter{
+			Type: "object",
+			Properties: map[string]any{
+				"task_id": map[string]any{"type": "string"},
+			},
+		},
+	},
+	SearchHistoryTool: {
+		Name:        SearchHistoryTool,
+		Description: "Search the history of the tasks for a text, optionally within a single task.",
+		Parameters: Parameter{
+			Type: "object",
+			Properties: map[string]any{
+				"query": map[string]any{"type": "string"},
+				"task_id": map[string]any{
+					"type":        "string",
+					"description": "Only search the history of this task.",
+				},
+			},
+			Required: []string{"query"},
+		},
+	},
+	InjectIntoTaskTool: {
+		Name: InjectIntoTaskTool,
+		Description: "Send an instruction from the user to a running task, " +
+			"the leader follows it on its next delegation.",
+		Parameters: Parameter{
+			Type: "object",
+			Properties: map[string]any{
+				"task_id": map[string]any{"type": "string"},
+				"message": map[string]any{"type": "string"},
+			},
+			Required: []string{"task_id", "message"},
+		},
+	},
+}
@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+)
+
+const ReportIssueTool = report_issue
+
+// Severities of a reported issue, every issue stops the step of the worker.
+const (
+	IssueLow      = "low"
+	IssueMedium   = "medium"
+	IssueHigh     = "high"
+	IssueCritical = "critical"
+)
+
+var issueSeverities = []string{IssueLow, IssueMedium, IssueHigh, IssueCritical}
+
+type IssueAction struct {
+	Reason   string `json:"reason"`
+	Severity string `json:"severity"`
+}
+
+// Level returns the severity of the issue, medium when the model sent none or an unknown one.
+func (a IssueAction) Level() string {
+	if slices.Contains(issueSeverities, a.Severity) {
+		return a.Severity
+	}
+	return IssueMedium
+}
+
+func executeIssueAction(action ToolTask) (string, error) {
+	return withParsed[IssueAction](action.Parameters, report_issue, func(a IssueAction) (string, error) {
+		if a.Reason == "" {
+			return "", fmt.Errorf("reason cannot be empty")
+		}
+		return fmt.Sprintf("Issue (%s) reported to the leader: %s", a.Level(), a.Reason), nil
+	})
+}
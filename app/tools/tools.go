@@ -9,16 +9,18 @@ import (
 
 // Presets
 const (
-	PresetDelegate = "delegate"
-	PresetApprover = "approver"
-	PresetAll      = "all"
+	PresetDelegate     = "delegate"
+	PresetApprover     = "approver"
+	PresetEventHandler = "event_handler"
+	PresetAll          = "all"
 )
 
 // Tools
 const (
-	delegate_task = "delegate_task"
-	report_issue  = "report_issue"
-	true_or_false = "true_or_false"
+	delegate_task  = "delegate_task"
+	delegate_tasks = "delegate_tasks"
+	report_issue   = "report_issue"
+	true_or_false  = "true_or_false"
 )
 
 type Tool struct {
@@ -37,44 +39,32 @@ type Parameter struct {
 type ToolTask struct {
 	Key        string         `json:"key"`
 	Parameters map[string]any `json:"parameters"`
+	TaskID     string         `json:"-"`
+	StepID     int            `json:"-"`
+	MemberKey  string         `json:"-"`
 }
 
 var allTools = map[string]Tool{
 	report_issue: {
-		Name:        report_issue,
-		Description: "Report a problem, limitation, or need for assistance to the leader.",
+		Name: report_issue,
+		Description: "Report a problem, limitation, or need for assistance to the leader. It ends your current task, " +
+			"use it only when you cannot continue on your own.",
 		Parameters: Parameter{
 			Type: "object",
 			Properties: map[string]any{
 				"reason": map[string]any{"type": "string"},
-			},
-			Required: []string{"reason"},
-		},
-	},
-	delegate_task: {
-		Name:        delegate_task,
-		Description: "Assign a clear, atomic task required to complete the main task to a worker from the team.",
-		Parameters: Parameter{
-			Type: "object",
-			Properties: map[string]any{
-				"worker": map[string]any{
+				"severity": map[string]any{
 					"type":        "string",
-					"description": "The worker to delegate the task to.",
-				},
-				"task": map[string]any{
-					"type":        "string",
-					"description": "A single, focused goal describing the exact action or deliverable expected.",
-					"maxLength":   100,
-				},
-				"context": map[string]any{
-					"type":        "string",
-					"description": "Optional brief context or background needed for the worker to execute the task effectively (avoid redundancy).",
-					"maxLength":   500,
+					"description": "How much the issue blocks the task, critical when nothing can continue without a human.",
+					"enum":        issueSeverities,
 				},
 			},
-			Required: []string{"worker_id", "objective"},
+			Required: []string{"reason"},
 		},
+		HandlerFunc: executeIssueAction,
 	},
+	delegate_task:  delegateTaskTool(nil),
+	delegate_tasks: delegateTasksTool(nil),
 	true_or_false: {
 		Name:        true_or_false,
 		Description: "Binary decision with a brief reason.",
@@ -101,11 +91,18 @@ func NewToolkitFromPreset(preset string) map[string]Tool {
 	case PresetDelegate:
 		return pick(
 			delegate_task,
+			delegate_tasks,
 		)
 	case PresetApprover:
 		return pick(
 			true_or_false,
 		)
+	case PresetEventHandler:
+		toolkit := make(map[string]Tool, len(eventHandlerTools))
+		for name, tool := range eventHandlerTools {
+			toolkit[name] = tool
+		}
+		return toolkit
 	case PresetAll:
 		keys := make([]string, 0, len(allTools))
 		for k := range allTools {
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+)
+
+// PanicError is a recovered panic, it keeps the stack trace so it can be
+// stored with the task that panicked.
+type PanicError struct {
+	Value any
+	Stack string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// RecoverPanic stops a panic of the calling goroutine and logs it with its
+// stack trace, it must be deferred. When err is not nil the panic is returned
+// through it as a *PanicError.
+func RecoverPanic(where string, err *error) {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+	panicErr := &PanicError{Value: rec, Stack: string(debug.Stack())}
+	log.Printf("❌ Panic recovered in %s: %v\nStack trace:\n%s", where, rec, panicErr.Stack)
+	if err != nil {
+		*err = panicErr
+	}
+}
@@ -109,3 +109,11 @@ func ReadFile(path string) (string, error) {
 	}
 	return string(b), nil
 }
+
+func Truncate(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	return string(runes[:maxLen]) + "..."
+}
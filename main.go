@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sort"
 	"syscall"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"GoWorkerAI/app/rag"
 	"GoWorkerAI/app/runtime"
 	"GoWorkerAI/app/storage"
+	"GoWorkerAI/app/teams"
 	"GoWorkerAI/app/tools"
 	"GoWorkerAI/app/utils"
 )
@@ -35,6 +37,9 @@ func main() {
 		log.Fatalf("❌ Failed to load configs: %v", err)
 	}
 
+	if cfg.Runtime.Workspace == "" {
+		cfg.Runtime.Workspace = os.Getenv("WORKER_FOLDER")
+	}
 	if err = cfg.Validate(); err != nil {
 		log.Fatalf("❌ Invalid configs: %v", err)
 	}
@@ -48,64 +53,74 @@ func main() {
 		log.Fatalf("❌ Failed to start global MCPs: %v", err)
 	}
 
-	teamName := os.Getenv("TEAM_NAME")
-	if teamName == "" {
-		teamName = "default"
+	defaultTeam := os.Getenv("TEAM_NAME")
+	if defaultTeam == "" {
+		defaultTeam = "default"
 	}
-
-	log.Printf("🏗️ Building team: %s\n", teamName)
-	team, err := cfg.BuildTeamByName(appCtx, teamName, mcpRegistry)
-	if err != nil {
-		log.Fatalf("❌ Failed to build team: %v", err)
+	if _, ok := cfg.Teams[defaultTeam]; !ok {
+		log.Fatalf("❌ Default team %s not found in configs", defaultTeam)
 	}
 
 	db := getDB()
 	model := getModel(db)
 	colors := utils.GetColors()
 
-	for _, m := range team.Members {
-		existingToolkit := m.GetToolKit()
-		if existingToolkit == nil {
-			existingToolkit = make(map[string]tools.Tool)
-		}
+	ragClient := rag.NewClient(model)
+	if err = ragClient.InitContext(appCtx); err != nil {
+		log.Printf("❌ Failed to init rag: %v", err)
+	}
 
-		toolsPreset := tools.NewToolkitFromPreset(m.GetToolsPreset())
-		for name, tool := range toolsPreset {
-			existingToolkit[name] = tool
-		}
+	runtimeCfg := cfg.Runtime
+	runtimeCfg.Schedules = cfg.Schedules
+	if os.Getenv("DRY_RUN") == "true" {
+		runtimeCfg.DryRun.Enabled = true
+	}
+	if runtimeCfg.DryRun.Enabled {
+		log.Println("🧪 Dry run enabled, tools are simulated")
+	}
 
-		for _, tool := range tools.AllRegisteredTools() {
-			if _, exists := existingToolkit[tool.Name]; !exists {
-				existingToolkit[tool.Name] = tool
-			}
+	teamNames := make([]string, 0, len(cfg.Teams))
+	for name := range cfg.Teams {
+		teamNames = append(teamNames, name)
+	}
+	sort.Strings(teamNames)
+
+	runtimes := make([]*runtime.Runtime, 0, len(teamNames))
+	for i, teamName := range teamNames {
+		log.Printf("🏗️ Building team: %s\n", teamName)
+		team, err := cfg.BuildTeamByName(appCtx, teamName, mcpRegistry)
+		if err != nil {
+			log.Fatalf("❌ Failed to build team %s: %v", teamName, err)
 		}
 
-		m.SetToolKit(existingToolkit)
-	}
+		setupToolkits(team)
 
-	auditLogger, err := utils.NewWorkerLogger("team_logs_"+time.Now().Format("20060102_150405"), colors[0], 10000)
-	if err != nil {
-		log.Fatalf("❌ Failed to create logger for the team: %v", err)
-	}
-	team.Audits = auditLogger
+		auditLogger, err := utils.NewWorkerLogger("team_logs_"+teamName+"_"+time.Now().Format("20060102_150405"),
+			colors[i%len(colors)], 10000)
+		if err != nil {
+			log.Fatalf("❌ Failed to create logger for team %s: %v", teamName, err)
+		}
+		team.Audits = auditLogger
+		// Only the default team runs its task on start, unless the team opts in.
+		if teamName != defaultTeam && !cfg.Teams[teamName].RunOnStart {
+			team.Task = nil
+		}
 
-	ragClient := rag.NewClient(model)
-	if err = ragClient.InitContext(appCtx); err != nil {
-		log.Printf("❌ Failed to init rag: %v", err)
+		runtimes = append(runtimes, runtime.NewRuntime(team, model, db, ragClient, runtimeCfg))
 	}
-
-	r := runtime.NewRuntime(team, model, db, ragClient)
+	router := runtime.NewRouter(defaultTeam, runtimes...)
 
 	clientRegistry := clients.NewRegistry()
 	defer clientRegistry.CloseAll()
 
-	if err = cfg.InitializeClients(clientRegistry, r); err != nil {
+	if err = cfg.InitializeClients(clientRegistry, router); err != nil {
 		log.Fatalf("❌ Failed to initialize clients: %v", err)
 	}
 
-	go r.Start(appCtx)
+	router.Start(appCtx)
 
 	log.Println("✅ All systems started. Press Ctrl+C to exit...")
+	log.Printf("👥 Active teams: %v (default: %s)\n", router.Teams(), defaultTeam)
 	log.Printf("📊 Active MCPs: %v\n", mcpRegistry.List())
 	log.Printf("🔧 Total tools available: %d\n", len(tools.AllRegisteredTools()))
 	log.Printf("🔌 Active clients: %d\n", len(clientRegistry.GetAll()))
@@ -114,6 +129,34 @@ func main() {
 	log.Println("🛑 Shutting down gracefully...")
 }
 
+// setupToolkits gives every member its preset and the registered tools, sub-teams included.
+func setupToolkits(team *teams.Team) {
+	for _, m := range team.Members {
+		if m.SubTeam != nil {
+			setupToolkits(m.SubTeam)
+			continue
+		}
+
+		existingToolkit := m.GetToolKit()
+		if existingToolkit == nil {
+			existingToolkit = make(map[string]tools.Tool)
+		}
+
+		toolsPreset := tools.NewToolkitFromPreset(m.GetToolsPreset())
+		for name, tool := range toolsPreset {
+			existingToolkit[name] = tool
+		}
+
+		for _, tool := range tools.AllRegisteredTools() {
+			if _, exists := existingToolkit[tool.Name]; !exists {
+				existingToolkit[tool.Name] = tool
+			}
+		}
+
+		m.SetToolKit(existingToolkit)
+	}
+}
+
 func getDB() storage.Interface {
 	return storage.NewSQLiteStorage()
 }